@@ -0,0 +1,184 @@
+package gexelizer
+
+import (
+	"errors"
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"io/fs"
+	"strings"
+)
+
+// AppendToFile appends data to the sheet of the .xlsx file at filename after its last non-empty row,
+// creating the file if it does not exist
+func AppendToFile[T any](filename string, data []T, opts ...Option) error {
+	options, err := newOptions(opts...)
+	if err != nil {
+		return err
+	}
+	file, err := OpenExcelizeFileWriter(filename, options.Password)
+	if errors.Is(err, fs.ErrNotExist) {
+		return WriteToFile(filename, data, opts...)
+	}
+	if err != nil {
+		return err
+	}
+	//Copy before appending, so the caller's slice is never written to
+	opts = append(opts[:len(opts):len(opts)], WithFile(file), WithAppend(true))
+	tw, err := NewTypeWriter[T](opts...)
+	if err != nil {
+		return err
+	}
+	if err = tw.Write(data); err != nil {
+		return err
+	}
+	return tw.WriteToFile(filename)
+}
+
+// prepareAppend moves the writer after the last non-empty row of its sheet and maps the headers onto the existing header,
+// adding the headers missing from it. Sheets without a header are written as usual
+func (w *TypeWriter[T]) prepareAppend() error {
+	rows, err := w.file.GetSheetRows(w.sheet)
+	if err != nil {
+		return err
+	}
+	lastRow := 0
+	for i, row := range rows {
+		for _, cell := range row {
+			if strings.TrimSpace(cell) != "" {
+				lastRow = i + 1
+				break
+			}
+		}
+	}
+	if lastRow < int(w.options.HeaderRow) {
+		return nil
+	}
+	//Columns of an existing sheet are never removed
+	w.options.KeepEmptyColumns = true
+	var existing []string
+	if header := rows[w.options.HeaderRow-1]; len(header) >= w.options.startColumn() {
+		existing = header[w.options.startColumn()-1:]
+	}
+	labels := make([]string, len(existing))
+	for i, label := range existing {
+		labels[i] = strings.TrimSpace(strings.ToLower(label))
+	}
+	w.columnOffsets = make([]int, len(w.headers))
+	w.sheetWidth = len(existing)
+	matched := 0
+	var missing []string
+	for i, col := range w.headers {
+		offset, exists := w.existingColumn(col, labels)
+		if exists {
+			matched++
+		} else {
+			offset = w.sheetWidth
+			w.sheetWidth++
+			missing = append(missing, w.options.headerLabel(col, w.typeInfo.nameToField[col]))
+		}
+		w.columnOffsets[i] = offset
+	}
+	if matched == 0 {
+		return fmt.Errorf("header of sheet %s does not match any column", w.sheet)
+	}
+	if len(missing) > 0 {
+		if err := w.file.SetStringRow(uint(w.options.startColumn()+len(existing)), w.options.HeaderRow, missing); err != nil {
+			return err
+		}
+	}
+	//The totals row is written again below the appended rows
+	if totals, err := w.isExistingTotalsRow(lastRow); err != nil {
+		return err
+	} else if totals {
+		if err := w.file.RemoveRow(uint(lastRow)); err != nil {
+			return err
+		}
+		lastRow--
+	}
+	w.columnContainsValues = make([]bool, len(w.headers))
+	w.columnLengths = make([]int, len(w.headers))
+	w.nextRowToWrite = uint(lastRow) + 1
+	if w.nextRowToWrite < w.options.DataStartRow {
+		w.nextRowToWrite = w.options.DataStartRow
+	}
+	w.firstDataRow = w.nextRowToWrite
+	return nil
+}
+
+// isExistingTotalsRow reports whether the sheet row is a totals row of T, an aggregated column of which holds a SUBTOTAL formula
+func (w *TypeWriter[T]) isExistingTotalsRow(row int) (bool, error) {
+	if row <= int(w.options.HeaderRow) || !w.hasTotals() {
+		return false, nil
+	}
+	for i, header := range w.headers {
+		if w.typeInfo.nameToField[header].aggregate == "" {
+			continue
+		}
+		cell, err := excelize.CoordinatesToCellName(w.headerColumn(i), row)
+		if err != nil {
+			return false, err
+		}
+		formula, err := w.file.GetCellFormula(w.sheet, cell)
+		if err != nil {
+			return false, err
+		}
+		if isSubtotalFormula(formula) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// existingColumn returns the offset of the existing header of col, matched by name, aliases and accepted labels
+func (w *TypeWriter[T]) existingColumn(col string, labels []string) (int, bool) {
+	fi := w.typeInfo.nameToField[col]
+	accepted := w.options.acceptedLabels(col, fi)
+	for i, label := range labels {
+		if aliased, exists := w.typeInfo.nameToField[label]; exists && strings.EqualFold(aliased.name, fi.name) {
+			return i, true
+		}
+		for _, acceptedLabel := range accepted {
+			if label != "" && label == strings.TrimSpace(strings.ToLower(acceptedLabel)) {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// headerColumn returns the 1-based sheet column of the header at index i while rows are written,
+// before empty columns are removed
+func (w *TypeWriter[T]) headerColumn(i int) int {
+	if w.columnOffsets != nil {
+		return w.options.startColumn() + w.columnOffsets[i]
+	}
+	return w.options.startColumn() + i
+}
+
+// columnNumber returns the 1-based sheet column of position k of visibleColumns, once empty columns are removed
+func (w *TypeWriter[T]) columnNumber(k int) int {
+	if w.columnOffsets != nil {
+		return w.headerColumn(w.visibleColumns[k])
+	}
+	return w.options.startColumn() + k
+}
+
+// columnCount returns the number of sheet columns from the start column the writer covers
+func (w *TypeWriter[T]) columnCount() int {
+	if w.columnOffsets != nil {
+		return w.sheetWidth
+	}
+	return len(w.visibleColumns)
+}
+
+// sheetRow places the values of row in their sheet columns when appending to a sheet whose header order differs
+func (w *TypeWriter[T]) sheetRow(row []any) []any {
+	if w.columnOffsets == nil {
+		return row
+	}
+	placed := make([]any, w.sheetWidth)
+	for i, value := range row {
+		placed[w.columnOffsets[i]] = value
+	}
+	return placed
+}
@@ -0,0 +1,128 @@
+package gexelizer
+
+import (
+	"bytes"
+	"github.com/xuri/excelize/v2"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAppend_RemapsExistingHeader(t *testing.T) {
+	type entry struct {
+		Level   string `gex:"column:level,aliases:severity"`
+		Message string `gex:"column:message"`
+		Count   int    `gex:"column:count"`
+	}
+	//An existing log with its own header order, a column unknown to entry and no count column
+	existing := excelize.NewFile()
+	_ = existing.SetSheetRow("Sheet1", "A1", &[]any{"Message", "Notes", "Severity"})
+	_ = existing.SetSheetRow("Sheet1", "A2", &[]any{"started", "first run", "info"})
+	buffer, err := existing.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := OpenExcelizeWriter(buffer, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer, err := NewTypeWriter[entry](WithFile(file), WithAppend(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Write([]entry{{Level: "warn", Message: "slow", Count: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Write([]entry{{Level: "error", Message: "failed", Count: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	appended, err := writer.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := sheetRows(t, appended.Bytes())
+	expected := [][]string{
+		{"Message", "Notes", "Severity", "Count"},
+		{"started", "first run", "info"},
+		{"slow", "", "warn", "2"},
+		{"failed", "", "error", "1"},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Fatalf("expected %v, got %v", expected, rows)
+	}
+
+	read, err := ReadExcel[entry](bytes.NewReader(appended.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 3 || read[0] != (entry{Level: "info", Message: "started"}) || read[2] != (entry{Level: "error", Message: "failed", Count: 1}) {
+		t.Fatalf("unexpected entries %+v", read)
+	}
+
+	unrelated := excelize.NewFile()
+	_ = unrelated.SetSheetRow("Sheet1", "A1", &[]any{"Name", "Age"})
+	if _, err := NewTypeWriter[entry](WithFile(&excelFile{file: unrelated}), WithAppend(true)); err == nil {
+		t.Fatal("expected a header without any matching column to be rejected")
+	}
+}
+
+func TestAppend_AppendToFile(t *testing.T) {
+	type entry struct {
+		Message string `gex:"column:message"`
+		Count   int    `gex:"column:count"`
+	}
+	filename := filepath.Join(t.TempDir(), "log.xlsx")
+	//The first call creates the file, the next ones continue it
+	if err := AppendToFile(filename, []entry{{Message: "a", Count: 1}}, WithSheet("Log")); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendToFile(filename, []entry{{Message: "b", Count: 2}, {Message: "c", Count: 3}}, WithSheet("Log")); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendToFile[entry](filename, nil, WithSheet("Log")); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadExcelFile[entry](filename, WithSheet("Log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []entry{{Message: "a", Count: 1}, {Message: "b", Count: 2}, {Message: "c", Count: 3}}
+	if !reflect.DeepEqual(read, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, read)
+	}
+}
+
+func TestAppend_RewritesTotalsRow(t *testing.T) {
+	type sale struct {
+		Item   string  `gex:"column:item"`
+		Amount float64 `gex:"column:amount,sum"`
+	}
+	filename := filepath.Join(t.TempDir(), "sales.xlsx")
+	if err := AppendToFile(filename, []sale{{Item: "a", Amount: 1}, {Item: "b", Amount: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendToFile(filename, []sale{{Item: "c", Amount: 4}}); err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := file.GetRows("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 5 || rows[3][0] != "c" || rows[4][0] != "Total" {
+		t.Fatalf("expected a single totals row below the appended rows, got %v", rows)
+	}
+	if formula, _ := file.GetCellFormula("Sheet1", "B5"); formula != "SUBTOTAL(109,B2:B4)" {
+		t.Fatalf("expected the totals to cover every row, got %q", formula)
+	}
+	read, err := ReadExcelFile[sale](filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 3 || read[2] != (sale{Item: "c", Amount: 4}) {
+		t.Fatalf("unexpected sales %+v", read)
+	}
+}
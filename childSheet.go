@@ -0,0 +1,209 @@
+package gexelizer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// childSheet is the sheet the slice elements of T are written to, see WithChildSheet
+type childSheet struct {
+	name string
+	// primary is the header index of the primary column, written first on every child row as the foreign key
+	primary int
+	// columns holds the header indexes of the slice element columns
+	columns []int
+	// sheet is the child sheet written to, numbered like the sheets of T once it rolls over, e.g. "Lines (2)"
+	sheet  string
+	number int
+	// lengths holds the length of the longest value of every header on the child sheet, for AutoFitColumns
+	lengths []int
+	nextRow uint
+}
+
+// analyzeChildSheet resolves the primary and slice element columns written to the child sheet of the options
+func (w *TypeWriter[T]) analyzeChildSheet() error {
+	if w.options.ChildSheet == "" {
+		return nil
+	}
+	if !w.typeInfo.containsSlice() {
+		return fmt.Errorf("child sheet %s needs a type with a slice", w.options.ChildSheet)
+	}
+	child := &childSheet{name: w.options.ChildSheet, primary: -1, sheet: w.options.ChildSheet, number: 1}
+	for i, header := range w.headers {
+		fi := w.typeInfo.nameToField[header]
+		if fi.isPrimaryKey {
+			child.primary = i
+		}
+		if !w.parentColumns[i] {
+			child.columns = append(child.columns, i)
+		}
+	}
+	if child.primary < 0 || !w.parentColumns[child.primary] {
+		return fmt.Errorf("child sheet %s needs a primary column outside of the slice", w.options.ChildSheet)
+	}
+	w.child = child
+	return nil
+}
+
+// childHeaders returns the header indexes of the child sheet columns, the primary column first
+func (c *childSheet) childHeaders() []int {
+	return append([]int{c.primary}, c.columns...)
+}
+
+// writeChildRows writes the slice elements of a value of T to the child sheet, each row starting with its primary key.
+// Like the sheet of T, the child sheet continues on the next sheet of its series once it holds MaxRowsPerSheet rows
+func (w *TypeWriter[T]) writeChildRows(rows [][]any, children int) error {
+	if children == 0 {
+		return nil
+	}
+	if w.child.nextRow != 0 && w.child.nextRow+uint(children)-1 > w.lastRow() {
+		if w.child.nextRow == w.options.DataStartRow {
+			return fmt.Errorf("%d child rows of a single value do not fit in %d rows per sheet", children, w.lastRow()-w.options.DataStartRow+1)
+		}
+		if err := w.finalizeChildSheet(); err != nil {
+			return err
+		}
+		w.child.number++
+		w.child.sheet = seriesSheetName(w.child.name, w.child.number)
+		w.child.nextRow = 0
+	}
+	if err := w.file.SetDefaultSheet(w.child.sheet); err != nil {
+		return err
+	}
+	indexes := w.child.childHeaders()
+	if w.child.nextRow == 0 {
+		labels := make([]string, 0, len(indexes))
+		for _, i := range indexes {
+			labels = append(labels, w.options.headerLabel(w.headers[i], w.typeInfo.nameToField[w.headers[i]]))
+		}
+		if err := w.file.SetStringRow(uint(w.options.startColumn()), w.options.HeaderRow, labels); err != nil {
+			return err
+		}
+		w.child.lengths = make([]int, len(w.headers))
+		for k, label := range labels {
+			w.child.lengths[indexes[k]] = utf8.RuneCountInString(label)
+		}
+		w.child.nextRow = w.options.DataStartRow
+		if w.child.nextRow+uint(children)-1 > w.lastRow() {
+			return fmt.Errorf("%d child rows of a single value do not fit in %d rows per sheet", children, w.lastRow()-w.options.DataStartRow+1)
+		}
+	}
+	for _, row := range rows[:children] {
+		w.toFormulas(row)
+		w.toLocation(row)
+		values := make([]any, len(indexes))
+		values[0] = rows[0][w.child.primary]
+		for k, i := range w.child.columns {
+			values[k+1] = row[i]
+		}
+		if err := w.file.SetRow(uint(w.options.startColumn()), w.child.nextRow, values); err != nil {
+			return err
+		}
+		for k, value := range values {
+			if value == nil {
+				continue
+			}
+			if length, ok := w.valueLength(indexes[k], value); ok && length > w.child.lengths[indexes[k]] {
+				w.child.lengths[indexes[k]] = length
+			}
+		}
+		w.child.nextRow++
+	}
+	return w.file.SetDefaultSheet(w.sheet)
+}
+
+// childWriter returns a copy of the writer over the current child sheet, placing the child columns from the start column,
+// so the child sheet is styled and laid out like the sheet of T
+func (w *TypeWriter[T]) childWriter() *TypeWriter[T] {
+	child := *w
+	child.sheet = w.child.sheet
+	child.visibleColumns = w.child.childHeaders()
+	child.columnOffsets = make([]int, len(w.headers))
+	for k, i := range child.visibleColumns {
+		child.columnOffsets[i] = k
+	}
+	child.sheetWidth = len(child.visibleColumns)
+	child.columnLengths = w.child.lengths
+	child.firstDataRow = w.options.DataStartRow
+	child.nextRowToWrite = w.child.nextRow
+	//Child rows have no summary rows, required header marks or table of their own
+	child.parentColumns = nil
+	child.template = false
+	child.options.Table = ""
+	return &child
+}
+
+// finalizeChildSheet styles and lays out the current child sheet like the sheet of T, once it is complete
+func (w *TypeWriter[T]) finalizeChildSheet() error {
+	if w.child == nil || w.child.nextRow == 0 {
+		return nil
+	}
+	child := w.childWriter()
+	if err := w.file.SetDefaultSheet(child.sheet); err != nil {
+		return err
+	}
+	if err := child.applyStyles(); err != nil {
+		return err
+	}
+	if err := child.applyLayout(); err != nil {
+		return err
+	}
+	return w.file.SetDefaultSheet(w.sheet)
+}
+
+// joinChildSheet reads the slice elements of the child sheet of the options and appends them to the values of result
+// with the same primary key. A child row whose key matches no value is an error, its value may have been left out
+func (t *TypeReader[T]) joinChildSheet(result []T) error {
+	child := &TypeReader[T]{file: t.file, options: t.options, childOnly: true}
+	child.options.Sheet = t.options.ChildSheet
+	child.options.ChildSheet = ""
+	if err := child.analyzeType(); err != nil {
+		return fmt.Errorf("child sheet %s: %w", t.options.ChildSheet, err)
+	}
+	children, err := child.Read()
+	if err != nil {
+		return fmt.Errorf("child sheet %s: %w", t.options.ChildSheet, err)
+	}
+	var primary fieldInfo
+	for _, col := range t.typeInfo.orderedColumns {
+		if fi := t.typeInfo.nameToField[col]; fi.isPrimaryKey {
+			primary = fi
+		}
+	}
+	sliceIndex := t.typeInfo.sliceFieldInfo.index
+	indexes := make(map[string]int, len(result))
+	for i := range result {
+		if key, ok := primaryKeyOf(reflect.ValueOf(&result[i]).Elem(), primary); ok {
+			indexes[key] = i
+		}
+	}
+	for i := range children {
+		childValue := reflect.ValueOf(&children[i]).Elem()
+		key, ok := primaryKeyOf(childValue, primary)
+		if !ok {
+			continue
+		}
+		index, exists := indexes[key]
+		if !exists {
+			return fmt.Errorf("child sheet %s: no value has the primary key %s of a child row", t.options.ChildSheet, key)
+		}
+		slice := reflect.ValueOf(&result[index]).Elem().FieldByIndex(sliceIndex)
+		slice.Set(reflect.AppendSlice(slice, childValue.FieldByIndex(sliceIndex)))
+	}
+	return nil
+}
+
+// primaryKeyOf returns the primary key of v the way primary keys are compared, ok is false if it is unset
+func primaryKeyOf(v reflect.Value, primary fieldInfo) (string, bool) {
+	field, err := v.FieldByIndexErr(primary.index)
+	if err != nil {
+		return "", false
+	}
+	field = reflect.Indirect(field)
+	if !field.IsValid() {
+		return "", false
+	}
+	return strings.ToLower(strings.TrimSpace(fmt.Sprint(field.Interface()))), true
+}
@@ -0,0 +1,152 @@
+package gexelizer
+
+import (
+	"bytes"
+	"github.com/xuri/excelize/v2"
+	"reflect"
+	"testing"
+)
+
+func TestChildSheet_WriteAndRead(t *testing.T) {
+	type line struct {
+		SKU      string `gex:"column:sku,required"`
+		Quantity int    `gex:"column:quantity"`
+		Shipped  Date   `gex:"column:shipped"`
+	}
+	type order struct {
+		ID       int    `gex:"column:id,primary"`
+		Customer string `gex:"column:customer,required"`
+		Lines    []line `gex:"column:lines"`
+		Placed   Date   `gex:"column:placed"`
+	}
+	data := []order{
+		{ID: 1, Customer: "Acme", Placed: "2024-03-01", Lines: []line{{SKU: "a", Quantity: 2, Shipped: "2024-03-02"}, {SKU: "b", Quantity: 1}}},
+		{ID: 2, Customer: "Globex", Placed: "2024-03-04"},
+		{ID: 3, Customer: "Initech", Placed: "2024-03-05", Lines: []line{{SKU: "c", Quantity: 5}}},
+	}
+	buffer, err := WriteExcelToBuffer(data, WithSheet("Orders"), WithChildSheet("Lines"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	orders, _ := file.GetRows("Orders")
+	expectedOrders := [][]string{
+		{"Id", "Customer", "Placed"},
+		{"1", "Acme", "2024-03-01"},
+		{"2", "Globex", "2024-03-04"},
+		{"3", "Initech", "2024-03-05"},
+	}
+	if !reflect.DeepEqual(orders, expectedOrders) {
+		t.Fatalf("expected %v, got %v", expectedOrders, orders)
+	}
+	lines, _ := file.GetRows("Lines")
+	expectedLines := [][]string{
+		{"Id", "Lines.sku", "Lines.quantity", "Lines.shipped"},
+		{"1", "a", "2", "2024-03-02"},
+		{"1", "b", "1"},
+		{"3", "c", "5"},
+	}
+	if !reflect.DeepEqual(lines, expectedLines) {
+		t.Fatalf("expected %v, got %v", expectedLines, lines)
+	}
+
+	read, err := ReadExcel[order](bytes.NewReader(buffer.Bytes()), WithSheet("Orders"), WithChildSheet("Lines"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, data) {
+		t.Fatalf("expected %+v, got %+v", data, read)
+	}
+
+	//Child rows are joined by key in any order
+	_ = file.SetSheetRow("Lines", "A2", &[]any{3, "c", 5})
+	_ = file.SetSheetRow("Lines", "A4", &[]any{1, "a", 2, nil})
+	shuffled, err := file.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err = ReadExcel[order](shuffled, WithSheet("Orders"), WithChildSheet("Lines"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read[0].Lines) != 2 || read[0].Lines[0].SKU != "b" || read[0].Lines[1].SKU != "a" || len(read[2].Lines) != 1 || read[2].Lines[0].SKU != "c" {
+		t.Fatalf("unexpected join %+v", read)
+	}
+	//Child rows of keys no value has are not dropped silently
+	_ = file.SetSheetRow("Lines", "A5", &[]any{9, "z", 1})
+	orphaned, err := file.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadExcel[order](orphaned, WithSheet("Orders"), WithChildSheet("Lines")); err == nil {
+		t.Fatal("expected a child row of an unknown key to be rejected")
+	}
+
+	type unkeyed struct {
+		Name  string
+		Lines []line
+	}
+	if _, err := NewTypeWriter[unkeyed](WithChildSheet("Lines")); err == nil {
+		t.Fatal("expected a child sheet without a primary column to be rejected")
+	}
+	if _, err := NewTypeWriter[order](WithSheet("Orders"), WithChildSheet("Orders")); err == nil {
+		t.Fatal("expected a child sheet equal to the sheet of T to be rejected")
+	}
+}
+
+func TestChildSheet_Series(t *testing.T) {
+	type line struct {
+		SKU    string  `gex:"column:sku"`
+		Amount float64 `gex:"column:amount,numfmt:4"`
+	}
+	type order struct {
+		ID    int    `gex:"column:id,primary"`
+		Lines []line `gex:"column:lines"`
+	}
+	data := []order{
+		{ID: 1, Lines: []line{{"a", 1}, {"b", 2}}},
+		{ID: 2, Lines: []line{{"c", 3}}},
+		{ID: 3, Lines: []line{{"d", 4}, {"e", 5}}},
+	}
+	buffer, err := WriteExcelToBuffer(data, WithSheet("Orders"), WithChildSheet("Lines"), WithMaxRowsPerSheet(3),
+		WithHeaderStyle(&excelize.Style{Font: &excelize.Font{Bold: true}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	//The lines of a value are never split across child sheets
+	for sheet, expected := range map[string][][]string{
+		"Lines":     {{"Id", "Lines.sku", "Lines.amount"}, {"1", "a", "1.00"}, {"1", "b", "2.00"}, {"2", "c", "3.00"}},
+		"Lines (2)": {{"Id", "Lines.sku", "Lines.amount"}, {"3", "d", "4.00"}, {"3", "e", "5.00"}},
+	} {
+		rows, err := file.GetRows(sheet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(rows, expected) {
+			t.Fatalf("expected %v on %s, got %v", expected, sheet, rows)
+		}
+		style, _ := file.GetCellStyle(sheet, "A1")
+		if definition, err := file.GetStyle(style); err != nil || definition.Font == nil || !definition.Font.Bold {
+			t.Fatalf("expected the header of %s styled like the sheet of T", sheet)
+		}
+	}
+
+	read, err := ReadExcel[order](bytes.NewReader(buffer.Bytes()), WithSheet("Orders"), WithChildSheet("Lines"), WithSheetSeries(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, data) {
+		t.Fatalf("expected %+v, got %+v", data, read)
+	}
+	tooMany := []order{{ID: 1, Lines: []line{{"a", 1}, {"b", 2}, {"c", 3}, {"d", 4}}}}
+	if _, err := WriteExcelToBuffer(tooMany, WithChildSheet("Lines"), WithMaxRowsPerSheet(3)); err == nil {
+		t.Fatal("expected child rows of a value larger than a sheet to be rejected")
+	}
+}
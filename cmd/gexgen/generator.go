@@ -0,0 +1,405 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const gexelizerPath = "github.com/gogotchuri/gexelizer"
+const mainTag = "gex"
+const ignoreTag = "-"
+
+// parsers maps the basic types supported by the gexelizer reader to their parse function, "" means no parsing
+var parsers = map[string]string{
+	"string":  "",
+	"int":     "ParseInt",
+	"int64":   "ParseInt64",
+	"uint":    "ParseUint",
+	"float64": "ParseFloat",
+	"bool":    "ParseBool",
+}
+
+var basicTypes = map[string]bool{
+	"bool": true, "string": true, "int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "float32": true, "float64": true,
+	"byte": true, "rune": true,
+}
+
+// leaf is a field written into a single column
+type leaf struct {
+	path     string   // Go field path, e.g. "Address.City"
+	expr     string   // access expression, e.g. "r.Address.City"
+	parents  []parent // pointer parents, outermost first
+	pointer  bool     // the field itself is a pointer
+	nilable  bool     // nil pointer is written as nil, as gexelizer skips nil struct pointers
+	readable bool     // the field can be parsed from a cell value
+	parser   string   // parse function, "" for strings
+	conv     string   // conversion applied to the parsed value, "" if none
+}
+
+// parent is a pointer to struct on the way to a leaf
+type parent struct {
+	expr     string
+	typeExpr string
+}
+
+type packageInfo struct {
+	fset    *token.FileSet
+	name    string
+	specs   map[string]*ast.TypeSpec
+	files   map[string]*ast.File // type name to the declaring file
+	methods map[string]map[string]bool
+}
+
+type generator struct {
+	pkg       *packageInfo
+	qualifier string
+	usesLib   bool
+	buf       bytes.Buffer
+}
+
+// generate parses the package in dir and returns the formatted source with row readers and writers for types
+// the returned bool reports whether any of the types is declared in a test file
+func generate(dir string, types []string) ([]byte, bool, error) {
+	pkg, err := loadPackage(dir, types[0])
+	if err != nil {
+		return nil, false, err
+	}
+	g := &generator{pkg: pkg, qualifier: "gexelizer."}
+	if pkg.name == "gexelizer" {
+		g.qualifier = ""
+	}
+	isTest := false
+	var body bytes.Buffer
+	for _, name := range types {
+		name = strings.TrimSpace(name)
+		spec, ok := pkg.specs[name]
+		if !ok {
+			return nil, false, fmt.Errorf("type %s not found in package %s", name, pkg.name)
+		}
+		st, ok := spec.Type.(*ast.StructType)
+		if !ok || spec.TypeParams != nil {
+			return nil, false, fmt.Errorf("type %s is not a non-generic struct", name)
+		}
+		if strings.HasSuffix(pkg.fset.Position(pkg.files[name].Pos()).Filename, "_test.go") {
+			isTest = true
+		}
+		var leaves []leaf
+		if err := g.collect(st, pkg.files[name], "r", "", nil, &leaves); err != nil {
+			return nil, false, fmt.Errorf("type %s: %w", name, err)
+		}
+		g.buf.Reset()
+		g.writeType(name, leaves)
+		body.Write(g.buf.Bytes())
+	}
+	var out bytes.Buffer
+	out.WriteString("// Code generated by gexgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", pkg.name)
+	if g.usesLib && g.qualifier != "" {
+		fmt.Fprintf(&out, "import %q\n\n", gexelizerPath)
+	}
+	out.Write(body.Bytes())
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, false, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return src, isTest, nil
+}
+
+func loadPackage(dir, typeName string) (*packageInfo, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(pkgs))
+	for name := range pkgs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pkg := &packageInfo{
+			fset:    fset,
+			name:    name,
+			specs:   make(map[string]*ast.TypeSpec),
+			files:   make(map[string]*ast.File),
+			methods: make(map[string]map[string]bool),
+		}
+		for _, file := range pkgs[name].Files {
+			pkg.addFile(file)
+		}
+		if _, ok := pkg.specs[typeName]; ok {
+			return pkg, nil
+		}
+	}
+	return nil, fmt.Errorf("type %s not found in %s", typeName, dir)
+}
+
+func (p *packageInfo) addFile(file *ast.File) {
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok {
+					p.specs[ts.Name.Name] = ts
+					p.files[ts.Name.Name] = file
+				}
+			}
+		case *ast.FuncDecl:
+			if decl.Recv == nil || len(decl.Recv.List) == 0 {
+				continue
+			}
+			recv := decl.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			if ident, ok := recv.(*ast.Ident); ok {
+				if p.methods[ident.Name] == nil {
+					p.methods[ident.Name] = make(map[string]bool)
+				}
+				p.methods[ident.Name][decl.Name.Name] = true
+			}
+		}
+	}
+}
+
+// isFlat reports whether gexelizer writes the named type as a single value instead of decomposing it
+func (p *packageInfo) isFlat(name string) bool {
+	return p.methods[name]["String"] || p.methods[name]["GexelizerValue"]
+}
+
+// collect appends the leaves of the struct to leaves, following the traversal rules of gexelizer
+func (g *generator) collect(st *ast.StructType, file *ast.File, expr, path string, parents []parent, leaves *[]leaf) error {
+	for _, field := range st.Fields.List {
+		if field.Tag != nil {
+			tag, err := strconv.Unquote(field.Tag.Value)
+			if err == nil && reflect.StructTag(tag).Get(mainTag) == ignoreTag {
+				continue
+			}
+		}
+		names := make([]string, 0, len(field.Names))
+		for _, name := range field.Names {
+			//Skip unexported field, embedded ones are traversed as gexelizer does
+			if name.IsExported() {
+				names = append(names, name.Name)
+			}
+		}
+		if len(field.Names) == 0 {
+			names = append(names, embeddedName(field.Type))
+		}
+		for _, name := range names {
+			if err := g.collectField(field.Type, file, expr+"."+name, path+name, parents, leaves); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *generator) collectField(typ ast.Expr, file *ast.File, expr, path string, parents []parent, leaves *[]leaf) error {
+	pointer := false
+	if star, ok := typ.(*ast.StarExpr); ok {
+		pointer = true
+		typ = star.X
+	}
+	l := leaf{path: path, expr: expr, parents: parents, pointer: pointer}
+	switch t := typ.(type) {
+	case *ast.Ident:
+		if basicTypes[t.Name] {
+			l.parser, l.readable = parsers[t.Name]
+			*leaves = append(*leaves, l)
+			return nil
+		}
+		spec, ok := g.pkg.specs[t.Name]
+		if !ok {
+			//Not declared in this package, write it as is
+			l.nilable = pointer
+			*leaves = append(*leaves, l)
+			return nil
+		}
+		return g.collectNamed(t.Name, spec, expr, path, parents, l, leaves)
+	case *ast.SelectorExpr:
+		if importPath(file, t.X) == gexelizerPath && t.Sel.Name == "Date" {
+			l.readable = true
+			l.conv = g.qualifier + "Date"
+			g.usesLib = true
+			*leaves = append(*leaves, l)
+			return nil
+		}
+		l.nilable = pointer
+		if importPath(file, t.X) == "time" && t.Sel.Name == "Time" {
+			l.readable = true
+			l.parser = "ParseTime"
+			*leaves = append(*leaves, l)
+			return nil
+		}
+		//Other types from other packages are written as is and are not parsed
+		*leaves = append(*leaves, l)
+		return nil
+	case *ast.StructType:
+		if pointer {
+			return fmt.Errorf("field %s: pointers to anonymous structs are not supported", path)
+		}
+		return g.collect(t, file, expr, path+".", parents, leaves)
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return fmt.Errorf("field %s: slices are not supported", path)
+		}
+		return fmt.Errorf("field %s: arrays are not supported", path)
+	default:
+		return fmt.Errorf("field %s: unsupported type %s", path, g.exprString(typ))
+	}
+}
+
+func (g *generator) collectNamed(name string, spec *ast.TypeSpec, expr, path string, parents []parent, l leaf, leaves *[]leaf) error {
+	underlying := spec.Type
+	for {
+		ident, ok := underlying.(*ast.Ident)
+		if !ok {
+			break
+		}
+		if basicTypes[ident.Name] {
+			l.parser, l.readable = parsers[ident.Name]
+			l.conv = name
+			*leaves = append(*leaves, l)
+			return nil
+		}
+		next, ok := g.pkg.specs[ident.Name]
+		if !ok {
+			break
+		}
+		underlying = next.Type
+	}
+	st, isStruct := underlying.(*ast.StructType)
+	if !isStruct || g.pkg.isFlat(name) {
+		l.nilable = l.pointer
+		*leaves = append(*leaves, l)
+		return nil
+	}
+	if l.pointer {
+		parents = append(parents[:len(parents):len(parents)], parent{expr: expr, typeExpr: name})
+	}
+	return g.collect(st, g.pkg.files[name], expr, path+".", parents, leaves)
+}
+
+func (g *generator) writeType(name string, leaves []leaf) {
+	g.printf("// GexelizerFields returns the field paths in the order used by GexelizerWriteRow and GexelizerReadRow\n")
+	g.printf("func (%s) GexelizerFields() []string {\n", name)
+	g.printf("return []string{\n")
+	for _, l := range leaves {
+		g.printf("%q,\n", l.path)
+	}
+	g.printf("}\n}\n\n")
+
+	g.printf("// GexelizerWriteRow stores the field values into values, following GexelizerFields order\n")
+	g.printf("func (r %s) GexelizerWriteRow(values []any) {\n", name)
+	for i, l := range leaves {
+		conditions := l.nilConditions()
+		if len(conditions) == 0 {
+			g.printf("values[%d] = %s\n", i, l.expr)
+			continue
+		}
+		g.printf("if %s {\nvalues[%d] = %s\n} else {\nvalues[%d] = nil\n}\n", strings.Join(conditions, " && "), i, l.expr, i)
+	}
+	g.printf("}\n\n")
+
+	for _, l := range leaves {
+		if !l.readable {
+			return
+		}
+	}
+	g.printf("// GexelizerReadRow parses values, following GexelizerFields order, into the fields. Empty values are skipped\n")
+	g.printf("func (r *%s) GexelizerReadRow(values []string) error {\n", name)
+	for i, l := range leaves {
+		g.printf("if values[%d] != \"\" {\n", i)
+		if l.parser == "" {
+			g.printf("value := values[%d]\n", i)
+		} else {
+			g.usesLib = true
+			g.printf("value, err := %s%s(values[%d])\n", g.qualifier, l.parser, i)
+			g.printf("if err != nil {\nreturn %sFieldError{Index: %d, Err: err}\n}\n", g.qualifier, i)
+		}
+		for _, p := range l.parents {
+			g.printf("if %s == nil {\n%s = new(%s)\n}\n", p.expr, p.expr, p.typeExpr)
+		}
+		assigned := "value"
+		if l.conv != "" {
+			assigned = l.conv + "(value)"
+		}
+		if l.pointer {
+			if l.conv != "" {
+				g.printf("converted := %s\n", assigned)
+				assigned = "converted"
+			}
+			assigned = "&" + assigned
+		}
+		g.printf("%s = %s\n}\n", l.expr, assigned)
+	}
+	g.printf("return nil\n}\n\n")
+}
+
+func (l leaf) nilConditions() []string {
+	conditions := make([]string, 0, len(l.parents)+1)
+	for _, p := range l.parents {
+		conditions = append(conditions, p.expr+" != nil")
+	}
+	if l.nilable {
+		conditions = append(conditions, l.expr+" != nil")
+	}
+	return conditions
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) exprString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	_ = printer.Fprint(&buf, g.pkg.fset, expr)
+	return buf.String()
+}
+
+// embeddedName returns the field name of an embedded field, which is its type name
+func embeddedName(typ ast.Expr) string {
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	switch t := typ.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return ""
+}
+
+// importPath returns the import path of the package referenced by x in file
+func importPath(file *ast.File, x ast.Expr) string {
+	ident, ok := x.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name == ident.Name {
+			return path
+		}
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate_MatchesCommittedOutput(t *testing.T) {
+	src, isTest, err := generate("../..", []string{"genRow", "genWriteOnly"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isTest {
+		t.Fatal("expected test types to be detected")
+	}
+	committed, err := os.ReadFile("../../genrow_gex_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, committed) {
+		t.Fatal("generated code is out of date, run go generate in the repository root")
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		err    string
+	}{
+		{
+			name:   "slice",
+			source: "type row struct {\n\tItems []item\n}\ntype item struct{ Name string }\n",
+			err:    "slices are not supported",
+		},
+		{
+			name:   "map",
+			source: "type row struct {\n\tItems map[string]int\n}\n",
+			err:    "unsupported type",
+		},
+		{
+			name:   "not a struct",
+			source: "type row string\n",
+			err:    "is not a non-generic struct",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "row.go"), []byte("package rows\n\n"+tt.source), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, _, err := generate(dir, []string{"row"})
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestGenerate_ExternalPackage(t *testing.T) {
+	dir := t.TempDir()
+	source := `package rows
+
+import (
+	"time"
+
+	gex "github.com/gogotchuri/gexelizer"
+)
+
+type Status string
+
+type Row struct {
+	Status  Status
+	Born    gex.Date
+	Created *time.Time
+	Count   *int
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "row.go"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src, isTest, err := generate(dir, []string{"Row"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isTest {
+		t.Fatal("Row is not declared in a test file")
+	}
+	for _, expected := range []string{
+		`import "github.com/gogotchuri/gexelizer"`,
+		"r.Status = Status(value)",
+		"r.Born = gexelizer.Date(value)",
+		"value, err := gexelizer.ParseTime(values[2])",
+		"r.Count = &value",
+		"if r.Created != nil {",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Fatalf("expected generated code to contain %q:\n%s", expected, src)
+		}
+	}
+}
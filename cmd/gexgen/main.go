@@ -0,0 +1,56 @@
+// Command gexgen generates reflection free row readers and writers for structs used with gexelizer.
+//
+// The generated methods implement gexelizer.GexRowWriter and gexelizer.GexRowReader, which
+// TypeWriter and TypeReader pick up automatically. Column names, order, defaults and the rest of
+// the gex tag semantics are still resolved by gexelizer, the generated code only replaces the
+// reflective field access and value parsing. Types containing a slice are not supported.
+//
+// Usage, next to the type declaration:
+//
+//	//go:generate go run github.com/gogotchuri/gexelizer/cmd/gexgen -type=Row
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("gexgen: ")
+	typeNames := flag.String("type", "", "comma-separated list of struct type names; must be set")
+	output := flag.String("output", "", "output file name; default <type>_gex.go or <type>_gex_test.go for test types")
+	flag.Parse()
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	types := strings.Split(*typeNames, ",")
+	src, isTest, err := generate(dir, types)
+	if err != nil {
+		log.Fatal(err)
+	}
+	outputName := *output
+	if outputName == "" {
+		suffix := "_gex.go"
+		if isTest {
+			suffix = "_gex_test.go"
+		}
+		outputName = strings.ToLower(types[0]) + suffix
+	}
+	//Relative output names are relative to the package directory
+	if !filepath.IsAbs(outputName) {
+		outputName = filepath.Join(dir, outputName)
+	}
+	if err := os.WriteFile(outputName, src, 0o644); err != nil {
+		log.Fatal(fmt.Errorf("writing output: %w", err))
+	}
+}
@@ -20,9 +20,31 @@ const (
 	omitEmptyTag  = "omitempty"
 	noprefixTag   = "noprefix"
 	requiredTag   = "required"
+	formulaTag    = "formula"
+	wrapTag       = "wrap"
 	columnTag     = "column:"
 	prefixTag     = "prefix:"
 	defaultTag    = "default:"
 	aliasesTag    = "aliases:"
 	orderTag      = "order:"
+	numFmtTag     = "numfmt:" //Built-in format id or a custom format, quoted if it has commas, e.g. numfmt:'#,##0.00'
+	widthTag      = "width:"
+	alignTag      = "align:"
+	oneOfTag      = "oneof:"
+	minTag        = "min:"
+	maxTag        = "max:"
+	errorMsgTag   = "errormsg:"
+	descTag       = "desc:"
+	labelTag      = "label:"
+	aggregateTag  = "aggregate:" //Alias of the aggregate tags, e.g. aggregate:sum for sum
+)
+
+// Aggregates of the column in the totals and subtotal rows, tags of their own.
+// min and max have no colon, unlike the min: and max: validations
+const (
+	aggregateSum   = "sum"
+	aggregateAvg   = "avg"
+	aggregateCount = "count"
+	aggregateMin   = "min"
+	aggregateMax   = "max"
 )
@@ -2,6 +2,9 @@ package gexelizer
 
 import (
 	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
 	"time"
 )
 
@@ -15,8 +18,13 @@ func (d Date) String() string {
 	return string(d)
 }
 
+// GexelizerValue returns the date as time.Time, so it is written as a native date, or as a string if it cannot be parsed
 func (d Date) GexelizerValue() any {
-	return string(d)
+	t, err := d.ToTime()
+	if err != nil {
+		return string(d)
+	}
+	return t
 }
 
 func (d Date) ToTime() (time.Time, error) {
@@ -32,8 +40,12 @@ func (d Date) ToTime() (time.Time, error) {
 		"02.01.06",
 	}
 
+	s := string(d)
+	if len(s) > 10 {
+		s = s[:10]
+	}
 	for _, format := range timeFormats {
-		t, err := time.Parse(format, string(d)[:10])
+		t, err := time.Parse(format, s)
 		if err == nil {
 			return t, nil
 		}
@@ -44,3 +56,77 @@ func (d Date) ToTime() (time.Time, error) {
 func DateFromTime(t time.Time) Date {
 	return Date(t.Format("2006-01-02"))
 }
+
+// Formula is a cell formula such as "=SUM(B2:B10)", written as a formula and read from the formula text of the cell.
+// Cells without a formula are read as their value
+type Formula string
+
+func (f Formula) String() string {
+	return string(f)
+}
+
+// expression returns the formula without the leading "=", as it is stored in the file
+func (f Formula) expression() string {
+	return strings.TrimPrefix(strings.TrimSpace(string(f)), "=")
+}
+
+// Hyperlink is a cell linking to URL and showing Text, or URL when Text is empty.
+// Links to a location in the workbook are written as "Sheet2!A1", optionally prefixed with "#"
+type Hyperlink struct {
+	URL  string
+	Text string
+}
+
+var hyperlinkType = reflect.TypeOf(Hyperlink{})
+
+func (h Hyperlink) GexelizerValue() any {
+	if h.Text == "" {
+		return h.URL
+	}
+	return h.Text
+}
+
+// linkType returns the excelize link type and the link of h
+func (h Hyperlink) linkType() (string, string) {
+	if strings.HasPrefix(h.URL, "#") {
+		return "Location", strings.TrimPrefix(h.URL, "#")
+	}
+	if !strings.Contains(h.URL, "://") && !strings.HasPrefix(h.URL, "mailto:") && strings.Contains(h.URL, "!") {
+		return "Location", h.URL
+	}
+	return "External", h.URL
+}
+
+// Image is a picture placed into its cell and scaled to it, Ext is the file extension such as ".png".
+// When Ext is empty it is detected from Data
+type Image struct {
+	Data []byte
+	Ext  string
+}
+
+var imageType = reflect.TypeOf(Image{})
+
+// GexelizerValue returns nil, images are placed over an empty cell
+func (i Image) GexelizerValue() any {
+	return nil
+}
+
+// extension returns the extension of the image with a leading dot
+func (i Image) extension() string {
+	if i.Ext != "" {
+		return "." + strings.TrimPrefix(strings.ToLower(i.Ext), ".")
+	}
+	switch http.DetectContentType(i.Data) {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/gif":
+		return ".gif"
+	case "image/bmp":
+		return ".bmp"
+	case "image/webp":
+		return ".webp"
+	}
+	return ""
+}
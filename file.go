@@ -7,6 +7,8 @@ import (
 	"github.com/xuri/excelize/v2"
 	"golang.org/x/text/encoding/unicode"
 	"io"
+	"reflect"
+	"strings"
 	"time"
 	"unicode/utf8"
 )
@@ -16,15 +18,71 @@ type ExcelFileWriter interface {
 	WriteTo(w io.Writer) (int64, error)
 	WriteToBuffer() (*bytes.Buffer, error)
 	SetCellValueOfSheet(sheet, axis string, value any) error
-	SetRow(row uint, values []any) error
-	SetStringRow(row uint, values []string) error
+	// SetRow writes values into the row starting at the 1-based column, leaving the values slice as it is
+	SetRow(column, row uint, values []any) error
+	// SetStringRow writes values into the row starting at the 1-based column
+	SetStringRow(column, row uint, values []string) error
 	RemoveColumn(column string) error
+	// RemoveRow removes the 1-based row of the default sheet, moving the rows below it up
+	RemoveRow(row uint) error
+	// GetSheetRows returns the rows of sheet, each up to its last non-empty cell
+	GetSheetRows(sheet string) ([][]string, error)
+	// GetCellFormula returns the formula of cell without the leading "=", or "" if it has none
+	GetCellFormula(sheet, cell string) (string, error)
 	GetDefaultSheet() string
 	SetDefaultSheet(sheet string) error
+	// SetPassword encrypts the saved file with password, an empty password saves it unencrypted
+	SetPassword(password string)
+	// AddTable formats a range of the default sheet as an Excel Table
+	AddTable(table *excelize.Table) error
+	// NewStyle registers style in the file and returns its id
+	NewStyle(style *excelize.Style) (int, error)
+	// SetCellStyle applies the style to the cells from start to end of the default sheet, e.g. "A2" and "A10"
+	SetCellStyle(start, end string, styleID int) error
+	// GetCellStyle returns the style id of the cell of the default sheet, 0 if it has none
+	GetCellStyle(cell string) (int, error)
+	// SetColWidth sets the width of the columns from start to end of the default sheet, e.g. "A" and "C"
+	SetColWidth(start, end string, width float64) error
+	// SetPanes sets the panes of the default sheet, e.g. to freeze rows
+	SetPanes(panes *excelize.Panes) error
+	// AutoFilter turns on filtering over the range ref of the default sheet, e.g. "A1:C10"
+	AutoFilter(ref string) error
+	// MergeCell merges the cells from start to end of the default sheet, e.g. "A2" and "A4"
+	MergeCell(start, end string) error
+	// AddComment adds a note with text to the cell of the default sheet
+	AddComment(cell, text string) error
+	// AddDataValidation adds a data validation to the default sheet
+	AddDataValidation(validation *excelize.DataValidation) error
+	// SetRowHeight sets the height of the 1-based row of the default sheet
+	SetRowHeight(row uint, height float64) error
+	// SetRowOutlineLevel sets the outline level of the 1-based row of the default sheet
+	SetRowOutlineLevel(row uint, level uint8) error
+	// SetSheetProps sets the properties of the default sheet, e.g. where outline summary rows are
+	SetSheetProps(props *excelize.SheetPropsOptions) error
+	// SetDefinedName defines a workbook wide name for the range ref of the default sheet, e.g. "$A$1:$C$10"
+	SetDefinedName(name, ref string) error
 	GetBaseFile() *excelize.File
 }
 type ExcelFileReader interface {
+	GetDefaultSheet() string
 	GetDefaultSheetRows() ([][]string, error)
+	GetSheetRows(sheet string) ([][]string, error)
+	// GetSheetRawRows returns the rows of sheet without number formats applied, e.g. dates as serial numbers
+	GetSheetRawRows(sheet string) ([][]string, error)
+	// UsesDate1904 reports whether date serial numbers of the workbook count from 1904 instead of 1900
+	UsesDate1904() (bool, error)
+	// GetCellFormula returns the formula of cell without the leading "=", or "" if it has none
+	GetCellFormula(sheet, cell string) (string, error)
+	// CalcCellValue calculates the formula of cell
+	CalcCellValue(sheet, cell string) (string, error)
+	// GetCellHyperLink returns the link target of cell, or "" if it has none
+	GetCellHyperLink(sheet, cell string) (string, error)
+	// GetSheetImages returns the first image anchored at every cell of sheet holding one, by cell name, e.g. "B2"
+	GetSheetImages(sheet string) (map[string]Image, error)
+	// FindTable returns the sheet and range of the Excel Table called name
+	FindTable(name string) (sheet, ref string, err error)
+	// FindDefinedName returns the sheet and range, or single cell, the defined name refers to
+	FindDefinedName(name string) (sheet, ref string, err error)
 }
 
 var _ ExcelFileWriter = (*excelFile)(nil)
@@ -35,6 +93,9 @@ type excelFile struct {
 	file              *excelize.File
 	rows              [][]string
 	defaultSheetIndex int
+	password          string
+	// hyperlinkStyle is the style of hyperlink cells, created with the first hyperlink
+	hyperlinkStyle int
 }
 
 func (f *excelFile) GetBaseFile() *excelize.File {
@@ -46,11 +107,70 @@ type xlsFile struct {
 	rows [][]string
 }
 
+func (x xlsFile) GetDefaultSheet() string {
+	sh, err := x.file.GetSheet(0)
+	if err != nil {
+		return ""
+	}
+	return sh.GetName()
+}
+
 func (x xlsFile) GetDefaultSheetRows() ([][]string, error) {
 	sh, err := x.file.GetSheet(0)
 	if err != nil {
 		return nil, err
 	}
+	return xlsSheetRows(sh), nil
+}
+
+func (x xlsFile) GetSheetRows(sheet string) ([][]string, error) {
+	for i := 0; i < x.file.GetNumberSheets(); i++ {
+		sh, err := x.file.GetSheet(i)
+		if err != nil {
+			return nil, err
+		}
+		if sh.GetName() == sheet {
+			return xlsSheetRows(sh), nil
+		}
+	}
+	return nil, fmt.Errorf("sheet %s does not exist", sheet)
+}
+
+// GetSheetRawRows returns the rows as they are read, .xls cells are not formatted
+func (x xlsFile) GetSheetRawRows(sheet string) ([][]string, error) {
+	return x.GetSheetRows(sheet)
+}
+
+// UsesDate1904 reports false, .xls cells are read formatted
+func (x xlsFile) UsesDate1904() (bool, error) {
+	return false, nil
+}
+
+func (x xlsFile) FindTable(name string) (string, string, error) {
+	return "", "", fmt.Errorf("tables are not supported in .xls files, table %s cannot be read", name)
+}
+
+func (x xlsFile) FindDefinedName(name string) (string, string, error) {
+	return "", "", fmt.Errorf("defined names are not supported in .xls files, %s cannot be read", name)
+}
+
+func (x xlsFile) GetCellFormula(sheet, cell string) (string, error) {
+	return "", fmt.Errorf("formulas are not supported in .xls files, %s!%s cannot be read", sheet, cell)
+}
+
+func (x xlsFile) CalcCellValue(sheet, cell string) (string, error) {
+	return "", fmt.Errorf("formulas are not supported in .xls files, %s!%s cannot be calculated", sheet, cell)
+}
+
+func (x xlsFile) GetCellHyperLink(sheet, cell string) (string, error) {
+	return "", fmt.Errorf("hyperlinks are not supported in .xls files, %s!%s cannot be read", sheet, cell)
+}
+
+func (x xlsFile) GetSheetImages(sheet string) (map[string]Image, error) {
+	return nil, fmt.Errorf("images are not supported in .xls files, images of %s cannot be read", sheet)
+}
+
+func xlsSheetRows(sh *xls.Sheet) [][]string {
 	fancyRows := sh.GetRows()
 	rows := make([][]string, len(fancyRows))
 	for i, row := range fancyRows {
@@ -68,21 +188,52 @@ func (x xlsFile) GetDefaultSheetRows() ([][]string, error) {
 			}
 		}
 	}
-	return rows, nil
+	return rows
 }
 
 func (f *excelFile) RemoveColumn(column string) error {
 	return f.file.RemoveCol(f.GetDefaultSheet(), column)
 }
 
+func (f *excelFile) RemoveRow(row uint) error {
+	return f.file.RemoveRow(f.GetDefaultSheet(), int(row))
+}
+
 func NewExcelizeWriter() ExcelFileWriter {
+	return newExcelFile("")
+}
+
+// newExcelFile creates a new file, naming its default sheet if sheet is not empty
+func newExcelFile(sheet string) *excelFile {
+	file := excelize.NewFile()
+	if sheet != "" {
+		_ = file.SetSheetName(file.GetSheetName(0), sheet)
+	}
 	return &excelFile{
-		file: excelize.NewFile(),
+		file: file,
+	}
+}
+
+// OpenExcelizeWriter opens the .xlsx file in reader for writing, e.g. to append rows with WithAppend
+func OpenExcelizeWriter(reader io.Reader, password string) (ExcelFileWriter, error) {
+	file, err := excelize.OpenReader(reader, excelize.Options{Password: password})
+	if err != nil {
+		return nil, err
+	}
+	return &excelFile{file: file, password: password}, nil
+}
+
+// OpenExcelizeFileWriter opens the .xlsx file at path for writing, e.g. to append rows with WithAppend
+func OpenExcelizeFileWriter(path string, password string) (ExcelFileWriter, error) {
+	file, err := excelize.OpenFile(path, excelize.Options{Password: password})
+	if err != nil {
+		return nil, err
 	}
+	return &excelFile{file: file, password: password}, nil
 }
 
-func readExcelFile(path string) (ExcelFileReader, error) {
-	file, err := excelize.OpenFile(path)
+func readExcelFile(path string, password string) (ExcelFileReader, error) {
+	file, err := excelize.OpenFile(path, excelize.Options{Password: password})
 	if err != nil {
 		return nil, err
 	}
@@ -93,7 +244,7 @@ func readExcelFile(path string) (ExcelFileReader, error) {
 	return excel, nil
 }
 
-func readXLSExcel(reader io.ReadSeeker) (efr ExcelFileReader, err error) {
+func readXLSExcel(reader io.ReadSeeker, password string) (efr ExcelFileReader, err error) {
 	//panic recover
 	defer func() {
 		if r := recover(); r != nil {
@@ -101,6 +252,9 @@ func readXLSExcel(reader io.ReadSeeker) (efr ExcelFileReader, err error) {
 			err = fmt.Errorf("panic: %v", r)
 		}
 	}()
+	if password != "" {
+		return nil, fmt.Errorf("password protected .xls files are not supported")
+	}
 	workbook, err := xls.OpenReader(reader)
 	if err != nil {
 		return nil, err
@@ -112,7 +266,7 @@ func readXLSExcel(reader io.ReadSeeker) (efr ExcelFileReader, err error) {
 	return excel, nil
 }
 
-func readExcel(reader io.Reader) (efr ExcelFileReader, err error) {
+func readExcel(reader io.Reader, password string) (efr ExcelFileReader, err error) {
 	//panic recover
 	defer func() {
 		if r := recover(); r != nil {
@@ -120,7 +274,7 @@ func readExcel(reader io.Reader) (efr ExcelFileReader, err error) {
 			err = fmt.Errorf("panic: %v", r)
 		}
 	}()
-	file, err := excelize.OpenReader(reader)
+	file, err := excelize.OpenReader(reader, excelize.Options{Password: password})
 	if err != nil {
 		return nil, err
 	}
@@ -134,44 +288,167 @@ func readExcel(reader io.Reader) (efr ExcelFileReader, err error) {
 func (f *excelFile) SetCellValueOfSheet(sheet, axis string, value any) error {
 	return f.file.SetCellValue(sheet, axis, value)
 }
-func (f *excelFile) SetStringRow(row uint, values []string) error {
-	return f.file.SetSheetRow(f.GetDefaultSheet(), fmt.Sprintf("A%d", row), &values)
+func (f *excelFile) SetStringRow(column, row uint, values []string) error {
+	cell, err := excelize.CoordinatesToCellName(int(column), int(row))
+	if err != nil {
+		return err
+	}
+	return f.file.SetSheetRow(f.GetDefaultSheet(), cell, &values)
 }
 
-var DateTimeFormat = time.RFC3339
-
-func (f *excelFile) SetRow(row uint, values []any) error {
+func (f *excelFile) SetRow(column, row uint, values []any) error {
+	cell, err := excelize.CoordinatesToCellName(int(column), int(row))
+	if err != nil {
+		return err
+	}
+	var formulas map[int]Formula
+	var links map[int]Hyperlink
+	var images map[int]Image
+	//Converted in a copy, the values of the caller are left as they are
+	values = append([]any(nil), values...)
 	for i, v := range values {
-		if gv, ok := v.(GexValuer); ok {
+		//Pointers are written as what they point to, nil pointers as empty cells
+		if pointer := reflect.ValueOf(v); pointer.Kind() == reflect.Ptr {
+			if pointer.IsNil() {
+				values[i] = nil
+				continue
+			}
+			v = pointer.Elem().Interface()
+			values[i] = v
+		}
+		if link, ok := v.(Hyperlink); ok {
+			if links == nil {
+				links = make(map[int]Hyperlink)
+			}
+			links[i] = link
+			values[i] = link.GexelizerValue()
+		} else if image, ok := v.(Image); ok {
+			if images == nil {
+				images = make(map[int]Image)
+			}
+			images[i] = image
+		} else if f, ok := v.(Formula); ok {
+			if formulas == nil {
+				formulas = make(map[int]Formula)
+			}
+			formulas[i] = f
+		} else if gv, ok := v.(GexValuer); ok {
 			values[i] = gv.GexelizerValue()
-		} else if t, ok := v.(time.Time); ok {
-			values[i] = t.Format(DateTimeFormat)
+		} else if _, ok := v.(time.Time); ok {
+			//Written as a native date, styled with the date format of the column
+			continue
 		} else if t, ok := v.(fmt.Stringer); ok {
 			values[i] = t.String()
 		}
 	}
-	return f.file.SetSheetRow(f.GetDefaultSheet(), fmt.Sprintf("A%d", row), &values)
+	if formulas != nil || images != nil {
+		//Formulas and images are set after the row, so their cells hold no value
+		for i := range formulas {
+			values[i] = nil
+		}
+		for i := range images {
+			values[i] = nil
+		}
+	}
+	if err := f.file.SetSheetRow(f.GetDefaultSheet(), cell, &values); err != nil {
+		return err
+	}
+	for i, formula := range formulas {
+		cell, err := excelize.CoordinatesToCellName(int(column)+i, int(row))
+		if err != nil {
+			return err
+		}
+		if err := f.file.SetCellFormula(f.GetDefaultSheet(), cell, formula.expression()); err != nil {
+			return err
+		}
+	}
+	for i, link := range links {
+		cell, err := excelize.CoordinatesToCellName(int(column)+i, int(row))
+		if err != nil {
+			return err
+		}
+		if err := f.setHyperlink(cell, link); err != nil {
+			return err
+		}
+	}
+	for i, image := range images {
+		if len(image.Data) == 0 {
+			continue
+		}
+		cell, err := excelize.CoordinatesToCellName(int(column)+i, int(row))
+		if err != nil {
+			return err
+		}
+		if err := f.file.AddPictureFromBytes(f.GetDefaultSheet(), cell, &excelize.Picture{
+			Extension: image.extension(),
+			File:      image.Data,
+			Format:    &excelize.GraphicOptions{AutoFit: true, LockAspectRatio: true},
+		}); err != nil {
+			return fmt.Errorf("error adding image at %s: %w", cell, err)
+		}
+	}
+	return nil
+}
+
+// setHyperlink links cell of the default sheet to link and styles it as a hyperlink
+func (f *excelFile) setHyperlink(cell string, link Hyperlink) error {
+	linkType, target := link.linkType()
+	if err := f.file.SetCellHyperLink(f.GetDefaultSheet(), cell, target, linkType); err != nil {
+		return err
+	}
+	if f.hyperlinkStyle == 0 {
+		style, err := f.file.NewStyle(&excelize.Style{Font: hyperlinkFont()})
+		if err != nil {
+			return err
+		}
+		f.hyperlinkStyle = style
+	}
+	return f.file.SetCellStyle(f.GetDefaultSheet(), cell, cell, f.hyperlinkStyle)
+}
+
+func (f *excelFile) SetPassword(password string) {
+	f.password = password
+}
+
+// saveOptions returns the options the file has to be saved with
+func (f *excelFile) saveOptions() []excelize.Options {
+	if f.password == "" {
+		return nil
+	}
+	return []excelize.Options{{Password: f.password}}
 }
 
 func (f *excelFile) WriteTo(w io.Writer) (int64, error) {
-	return f.file.WriteTo(w)
+	return f.file.WriteTo(w, f.saveOptions()...)
 }
 
 func (f *excelFile) WriteToBuffer() (*bytes.Buffer, error) {
-	return f.file.WriteToBuffer()
+	if f.password == "" {
+		return f.file.WriteToBuffer()
+	}
+	buffer := &bytes.Buffer{}
+	_, err := f.file.WriteTo(buffer, f.saveOptions()...)
+	return buffer, err
 }
 
 func (f *excelFile) SaveAs(path string) error {
-	return f.file.SaveAs(path)
+	return f.file.SaveAs(path, f.saveOptions()...)
 }
 
 func (f *excelFile) GetRows(sheet string) ([][]string, error) {
-	if f.rows != nil {
+	if f.rows != nil && sheet == f.GetDefaultSheet() {
 		return f.rows, nil
 	}
 	return f.file.GetRows(sheet)
 }
 
+func (f *excelFile) GetSheetRows(sheet string) ([][]string, error) {
+	if index, err := f.file.GetSheetIndex(sheet); err != nil || index < 0 {
+		return nil, fmt.Errorf("sheet %s does not exist", sheet)
+	}
+	return f.GetRows(sheet)
+}
+
 func (f *excelFile) SetDefaultSheet(sheet string) error {
 	index, err := f.file.GetSheetIndex(sheet)
 	if err == nil && index >= 0 {
@@ -194,3 +471,164 @@ func (f *excelFile) GetDefaultSheetRows() ([][]string, error) {
 	sheet := f.GetDefaultSheet()
 	return f.GetRows(sheet)
 }
+
+func (f *excelFile) FindTable(name string) (string, string, error) {
+	for _, sheet := range f.file.GetSheetList() {
+		tables, err := f.file.GetTables(sheet)
+		if err != nil {
+			return "", "", err
+		}
+		for _, table := range tables {
+			if strings.EqualFold(table.Name, name) {
+				return sheet, table.Range, nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("table %s does not exist", name)
+}
+
+func (f *excelFile) AddTable(table *excelize.Table) error {
+	return f.file.AddTable(f.GetDefaultSheet(), table)
+}
+
+func (f *excelFile) FindDefinedName(name string) (string, string, error) {
+	for _, definedName := range f.file.GetDefinedName() {
+		if strings.EqualFold(definedName.Name, name) {
+			return splitSheetReference(definedName.RefersTo)
+		}
+	}
+	return "", "", fmt.Errorf("defined name %s does not exist", name)
+}
+
+func (f *excelFile) SetDefinedName(name, ref string) error {
+	return f.file.SetDefinedName(&excelize.DefinedName{
+		Name:     name,
+		RefersTo: sheetReference(f.GetDefaultSheet(), ref),
+	})
+}
+
+// sheetReference returns the reference to ref of sheet, e.g. "'My Sheet'!$A$1:$D$20", the reverse of splitSheetReference
+func sheetReference(sheet, ref string) string {
+	return "'" + strings.ReplaceAll(sheet, "'", "''") + "'!" + ref
+}
+
+// splitSheetReference splits a reference such as "'My Sheet'!$A$1:$D$20" into the sheet and the range
+func splitSheetReference(reference string) (string, string, error) {
+	reference = strings.TrimPrefix(strings.TrimSpace(reference), "=")
+	separator := strings.LastIndex(reference, "!")
+	if separator <= 0 || strings.ContainsAny(reference, ",()") {
+		return "", "", fmt.Errorf("reference %s is not a range of a single sheet", reference)
+	}
+	sheet := reference[:separator]
+	if len(sheet) > 1 && strings.HasPrefix(sheet, "'") && strings.HasSuffix(sheet, "'") {
+		sheet = strings.ReplaceAll(sheet[1:len(sheet)-1], "''", "'")
+	}
+	return sheet, reference[separator+1:], nil
+}
+
+func (f *excelFile) GetCellFormula(sheet, cell string) (string, error) {
+	return f.file.GetCellFormula(sheet, cell)
+}
+
+func (f *excelFile) CalcCellValue(sheet, cell string) (string, error) {
+	return f.file.CalcCellValue(sheet, cell)
+}
+
+func (f *excelFile) GetCellHyperLink(sheet, cell string) (string, error) {
+	_, target, err := f.file.GetCellHyperLink(sheet, cell)
+	return target, err
+}
+
+func (f *excelFile) GetSheetImages(sheet string) (map[string]Image, error) {
+	cells, err := f.file.GetPictureCells(sheet)
+	if err != nil {
+		return nil, err
+	}
+	images := make(map[string]Image, len(cells))
+	for _, cell := range cells {
+		pictures, err := f.file.GetPictures(sheet, cell)
+		if err != nil {
+			return nil, err
+		}
+		if len(pictures) == 0 {
+			continue
+		}
+		//Cell names are normalized, so they match the cells rows are read from
+		column, row, err := excelize.CellNameToCoordinates(cell)
+		if err != nil {
+			return nil, err
+		}
+		if cell, err = excelize.CoordinatesToCellName(column, row); err != nil {
+			return nil, err
+		}
+		if _, exists := images[cell]; !exists {
+			images[cell] = Image{Data: pictures[0].File, Ext: pictures[0].Extension}
+		}
+	}
+	return images, nil
+}
+
+func (f *excelFile) SetRowHeight(row uint, height float64) error {
+	return f.file.SetRowHeight(f.GetDefaultSheet(), int(row), height)
+}
+
+func (f *excelFile) NewStyle(style *excelize.Style) (int, error) {
+	return f.file.NewStyle(style)
+}
+
+func (f *excelFile) SetCellStyle(start, end string, styleID int) error {
+	return f.file.SetCellStyle(f.GetDefaultSheet(), start, end, styleID)
+}
+
+func (f *excelFile) GetCellStyle(cell string) (int, error) {
+	return f.file.GetCellStyle(f.GetDefaultSheet(), cell)
+}
+
+func (f *excelFile) SetColWidth(start, end string, width float64) error {
+	return f.file.SetColWidth(f.GetDefaultSheet(), start, end, width)
+}
+
+// hyperlinkFont is the font of hyperlink cells
+func hyperlinkFont() *excelize.Font {
+	return &excelize.Font{Color: "0563C1", Underline: "single"}
+}
+
+func (f *excelFile) SetRowOutlineLevel(row uint, level uint8) error {
+	return f.file.SetRowOutlineLevel(f.GetDefaultSheet(), int(row), level)
+}
+
+func (f *excelFile) SetSheetProps(props *excelize.SheetPropsOptions) error {
+	return f.file.SetSheetProps(f.GetDefaultSheet(), props)
+}
+
+func (f *excelFile) SetPanes(panes *excelize.Panes) error {
+	return f.file.SetPanes(f.GetDefaultSheet(), panes)
+}
+
+func (f *excelFile) AutoFilter(ref string) error {
+	return f.file.AutoFilter(f.GetDefaultSheet(), ref, nil)
+}
+
+func (f *excelFile) GetSheetRawRows(sheet string) ([][]string, error) {
+	return f.file.GetRows(sheet, excelize.Options{RawCellValue: true})
+}
+
+func (f *excelFile) UsesDate1904() (bool, error) {
+	props, err := f.file.GetWorkbookProps()
+	if err != nil {
+		return false, err
+	}
+	return props.Date1904 != nil && *props.Date1904, nil
+}
+
+func (f *excelFile) AddDataValidation(validation *excelize.DataValidation) error {
+	return f.file.AddDataValidation(f.GetDefaultSheet(), validation)
+}
+
+func (f *excelFile) AddComment(cell, text string) error {
+	return f.file.AddComment(f.GetDefaultSheet(), excelize.Comment{Cell: cell, Author: "gexelizer", Text: text})
+}
+
+func (f *excelFile) MergeCell(start, end string) error {
+	return f.file.MergeCell(f.GetDefaultSheet(), start, end)
+}
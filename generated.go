@@ -0,0 +1,70 @@
+package gexelizer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// GexRowWriter is implemented by struct types with a generated row writer (see cmd/gexgen).
+// TypeWriter picks it up automatically and skips reflection when every column maps to a generated field.
+type GexRowWriter interface {
+	// GexelizerFields returns Go field paths (e.g. "Address.City") in the order used by GexelizerWriteRow
+	GexelizerFields() []string
+	// GexelizerWriteRow stores the value of every field into values, nil for fields behind a nil pointer
+	GexelizerWriteRow(values []any)
+}
+
+// GexRowReader is implemented by pointers to struct types with a generated row reader (see cmd/gexgen).
+// TypeReader picks it up automatically and skips reflection when every column maps to a generated field.
+type GexRowReader interface {
+	// GexelizerFields returns Go field paths (e.g. "Address.City") in the order used by GexelizerReadRow
+	GexelizerFields() []string
+	// GexelizerReadRow parses values into the fields, empty values leave the field untouched
+	GexelizerReadRow(values []string) error
+}
+
+// FieldError is returned by generated readers when the value of the field at Index cannot be parsed
+type FieldError struct {
+	Index int
+	Err   error
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("field %d: %v", e.Index, e.Err)
+}
+
+// generatedFieldPositions maps every ordered column of the type to the position of its field in fields
+// it returns false if any of the columns has no generated counterpart, in which case reflection should be used
+func generatedFieldPositions(info typeInfo, fields []string) ([]int, bool) {
+	if info.containsSlice() || info.t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	fieldToPosition := make(map[string]int, len(fields))
+	for i, field := range fields {
+		fieldToPosition[field] = i
+	}
+	positions := make([]int, len(info.orderedColumns))
+	for i, col := range info.orderedColumns {
+		position, ok := fieldToPosition[fieldPath(info.t, info.nameToField[col].index)]
+		if !ok {
+			return nil, false
+		}
+		positions[i] = position
+	}
+	return positions, true
+}
+
+// fieldPath returns the dot separated Go field names leading to the field at index
+func fieldPath(t reflect.Type, index []int) string {
+	names := make([]string, 0, len(index))
+	for _, i := range index {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		field := t.Field(i)
+		names = append(names, field.Name)
+		t = field.Type
+	}
+	return strings.Join(names, ".")
+}
@@ -0,0 +1,188 @@
+package gexelizer
+
+import (
+	"bytes"
+	"github.com/xuri/excelize/v2"
+	"reflect"
+	"testing"
+	"time"
+)
+
+//go:generate go run ./cmd/gexgen -type=genRow,genWriteOnly
+
+type genAddress struct {
+	Street string `gex:"column:street"`
+	Number int    `gex:"column:number"`
+}
+
+type genBase struct {
+	ID uint `gex:"column:id,primary"`
+}
+
+type genRow struct {
+	genBase
+	Name     string      `gex:"column:name,required"`
+	Age      int         `gex:"aliases:years"`
+	Balance  float64     `gex:"default:1.5"`
+	Active   bool        `gex:"order:10"`
+	Big      int64       `gex:"column:big"`
+	Born     Date        `gex:"column:born"`
+	Address  *genAddress `gex:"omitempty"`
+	Shipping genAddress  `gex:"prefix:ship_"`
+	Ignored  string      `gex:"-"`
+	private  string
+}
+
+// reflRow has the fields and tags of genRow, but none of the generated methods
+type reflRow genRow
+
+type genWriteOnly struct {
+	Name    string     `gex:"column:name"`
+	Created time.Time  `gex:"column:created"`
+	Updated *time.Time `gex:"column:updated"`
+	Small   int8       `gex:"column:small"`
+}
+
+type reflWriteOnly genWriteOnly
+
+func TestGenerated_UsedWhenPresent(t *testing.T) {
+	w, err := NewTypeWriter[genRow]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.fieldPositions == nil {
+		t.Fatal("expected the generated writer to be used")
+	}
+	rw, err := NewTypeWriter[reflRow]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rw.fieldPositions != nil {
+		t.Fatal("expected reflection to be used")
+	}
+	info, err := analyzeType(reflect.TypeOf(genRow{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := generatedFieldPositions(info, (&genRow{}).GexelizerFields()); !ok {
+		t.Fatal("expected every column to map to a generated field")
+	}
+	if _, ok := any(&genWriteOnly{}).(GexRowReader); ok {
+		t.Fatal("int8 fields can not be parsed, no reader should be generated")
+	}
+}
+
+func TestGenerated_IdenticalToReflection(t *testing.T) {
+	data := []genRow{
+		{
+			genBase:  genBase{ID: 1},
+			Name:     "John",
+			Age:      30,
+			Balance:  10.25,
+			Active:   true,
+			Big:      1 << 40,
+			Born:     "2001-02-03",
+			Address:  &genAddress{Street: "Main", Number: 5},
+			Shipping: genAddress{Street: "Side", Number: 7},
+			Ignored:  "ignored",
+		},
+		{
+			genBase: genBase{ID: 2},
+			Name:    "Jane",
+			Age:     25,
+		},
+	}
+	reflData := make([]reflRow, len(data))
+	for i := range data {
+		reflData[i] = reflRow(data[i])
+	}
+	generatedBuffer, err := WriteExcelToBuffer(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reflBuffer, err := WriteExcelToBuffer(reflData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	generatedRows := sheetRows(t, generatedBuffer.Bytes())
+	reflRows := sheetRows(t, reflBuffer.Bytes())
+	if !reflect.DeepEqual(generatedRows, reflRows) {
+		t.Fatalf("written rows differ:\ngenerated: %v\nreflection: %v", generatedRows, reflRows)
+	}
+
+	generatedRead, err := ReadExcel[genRow](bytes.NewReader(reflBuffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reflRead, err := ReadExcel[reflRow](bytes.NewReader(reflBuffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(generatedRead) != len(reflRead) {
+		t.Fatalf("expected %d rows, got %d", len(reflRead), len(generatedRead))
+	}
+	for i := range generatedRead {
+		if !reflect.DeepEqual(reflRow(generatedRead[i]), reflRead[i]) {
+			t.Fatalf("row %d differs:\ngenerated: %+v\nreflection: %+v", i, generatedRead[i], reflRead[i])
+		}
+	}
+	if generatedRead[1].Address != nil {
+		t.Fatal("address should stay nil")
+	}
+}
+
+func TestGenerated_ErrorsIdenticalToReflection(t *testing.T) {
+	file := excelize.NewFile()
+	_ = file.SetSheetRow("Sheet1", "A1", &[]any{"id", "name", "age"})
+	_ = file.SetSheetRow("Sheet1", "A2", &[]any{"1", "John", "thirty"})
+	buffer, err := file.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, generatedErr := ReadExcel[genRow](bytes.NewReader(buffer.Bytes()))
+	_, reflErr := ReadExcel[reflRow](bytes.NewReader(buffer.Bytes()))
+	if generatedErr == nil || reflErr == nil {
+		t.Fatalf("expected errors, got %v and %v", generatedErr, reflErr)
+	}
+	if generatedErr.Error() != reflErr.Error() {
+		t.Fatalf("errors differ:\ngenerated: %v\nreflection: %v", generatedErr, reflErr)
+	}
+}
+
+func TestGenerated_WriteOnlyIdenticalToReflection(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	data := []genWriteOnly{
+		{Name: "John", Created: created, Updated: &created, Small: 3},
+		{Name: "Jane", Created: created},
+	}
+	reflData := make([]reflWriteOnly, len(data))
+	for i := range data {
+		reflData[i] = reflWriteOnly(data[i])
+	}
+	generatedBuffer, err := WriteExcelToBuffer(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reflBuffer, err := WriteExcelToBuffer(reflData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	generatedRows := sheetRows(t, generatedBuffer.Bytes())
+	reflRows := sheetRows(t, reflBuffer.Bytes())
+	if !reflect.DeepEqual(generatedRows, reflRows) {
+		t.Fatalf("written rows differ:\ngenerated: %v\nreflection: %v", generatedRows, reflRows)
+	}
+}
+
+func sheetRows(t *testing.T, data []byte) [][]string {
+	t.Helper()
+	file, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := file.GetRows(file.GetSheetName(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
@@ -0,0 +1,141 @@
+// Code generated by gexgen. DO NOT EDIT.
+
+package gexelizer
+
+// GexelizerFields returns the field paths in the order used by GexelizerWriteRow and GexelizerReadRow
+func (genRow) GexelizerFields() []string {
+	return []string{
+		"genBase.ID",
+		"Name",
+		"Age",
+		"Balance",
+		"Active",
+		"Big",
+		"Born",
+		"Address.Street",
+		"Address.Number",
+		"Shipping.Street",
+		"Shipping.Number",
+	}
+}
+
+// GexelizerWriteRow stores the field values into values, following GexelizerFields order
+func (r genRow) GexelizerWriteRow(values []any) {
+	values[0] = r.genBase.ID
+	values[1] = r.Name
+	values[2] = r.Age
+	values[3] = r.Balance
+	values[4] = r.Active
+	values[5] = r.Big
+	values[6] = r.Born
+	if r.Address != nil {
+		values[7] = r.Address.Street
+	} else {
+		values[7] = nil
+	}
+	if r.Address != nil {
+		values[8] = r.Address.Number
+	} else {
+		values[8] = nil
+	}
+	values[9] = r.Shipping.Street
+	values[10] = r.Shipping.Number
+}
+
+// GexelizerReadRow parses values, following GexelizerFields order, into the fields. Empty values are skipped
+func (r *genRow) GexelizerReadRow(values []string) error {
+	if values[0] != "" {
+		value, err := ParseUint(values[0])
+		if err != nil {
+			return FieldError{Index: 0, Err: err}
+		}
+		r.genBase.ID = value
+	}
+	if values[1] != "" {
+		value := values[1]
+		r.Name = value
+	}
+	if values[2] != "" {
+		value, err := ParseInt(values[2])
+		if err != nil {
+			return FieldError{Index: 2, Err: err}
+		}
+		r.Age = value
+	}
+	if values[3] != "" {
+		value, err := ParseFloat(values[3])
+		if err != nil {
+			return FieldError{Index: 3, Err: err}
+		}
+		r.Balance = value
+	}
+	if values[4] != "" {
+		value, err := ParseBool(values[4])
+		if err != nil {
+			return FieldError{Index: 4, Err: err}
+		}
+		r.Active = value
+	}
+	if values[5] != "" {
+		value, err := ParseInt64(values[5])
+		if err != nil {
+			return FieldError{Index: 5, Err: err}
+		}
+		r.Big = value
+	}
+	if values[6] != "" {
+		value := values[6]
+		r.Born = Date(value)
+	}
+	if values[7] != "" {
+		value := values[7]
+		if r.Address == nil {
+			r.Address = new(genAddress)
+		}
+		r.Address.Street = value
+	}
+	if values[8] != "" {
+		value, err := ParseInt(values[8])
+		if err != nil {
+			return FieldError{Index: 8, Err: err}
+		}
+		if r.Address == nil {
+			r.Address = new(genAddress)
+		}
+		r.Address.Number = value
+	}
+	if values[9] != "" {
+		value := values[9]
+		r.Shipping.Street = value
+	}
+	if values[10] != "" {
+		value, err := ParseInt(values[10])
+		if err != nil {
+			return FieldError{Index: 10, Err: err}
+		}
+		r.Shipping.Number = value
+	}
+	return nil
+}
+
+// GexelizerFields returns the field paths in the order used by GexelizerWriteRow and GexelizerReadRow
+func (genWriteOnly) GexelizerFields() []string {
+	return []string{
+		"Name",
+		"Created",
+		"Updated",
+		"Small",
+	}
+}
+
+// GexelizerWriteRow stores the field values into values, following GexelizerFields order
+func (r genWriteOnly) GexelizerWriteRow(values []any) {
+	values[0] = r.Name
+	values[1] = r.Created
+	if r.Updated != nil {
+		values[2] = r.Updated
+	} else {
+		values[2] = nil
+	}
+	values[3] = r.Small
+}
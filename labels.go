@@ -0,0 +1,72 @@
+package gexelizer
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// HeaderFormatter returns the header written for column, given the label it would get otherwise.
+// column is the key columns are matched by, such as "address.city"
+type HeaderFormatter func(column, label string) string
+
+// Translations maps a language to the header labels of columns in that language, keyed by column, e.g.
+// Translations{"de": {"name": "Name", "address.city": "Stadt"}}
+type Translations map[string]map[string]string
+
+// label returns the translation of column in language, column keys are matched ignoring case
+func (t Translations) label(language, column string) (string, bool) {
+	labels, ok := t[language]
+	if !ok {
+		return "", false
+	}
+	if label, ok := labels[column]; ok {
+		return label, true
+	}
+	for key, label := range labels {
+		if strings.EqualFold(key, column) {
+			return label, true
+		}
+	}
+	return "", false
+}
+
+// headerLabel returns the header written for column: its translation in the language of the options,
+// its label tag or the capitalized column, passed through the header formatter of the options
+func (o Options) headerLabel(column string, fi fieldInfo) string {
+	label, ok := o.Translations.label(o.Language, column)
+	if !ok {
+		label = fi.label
+	}
+	if label == "" {
+		label = capitalize(column)
+	}
+	if o.HeaderFormatter != nil {
+		return o.HeaderFormatter(column, label)
+	}
+	return label
+}
+
+// acceptedLabels returns the headers read as column besides its name and aliases:
+// its label tag, its translations in every language and the header it is written with
+func (o Options) acceptedLabels(column string, fi fieldInfo) []string {
+	labels := make([]string, 0, len(o.Translations)+2)
+	if fi.label != "" {
+		labels = append(labels, fi.label)
+	}
+	for language := range o.Translations {
+		if label, ok := o.Translations.label(language, column); ok {
+			labels = append(labels, label)
+		}
+	}
+	return append(labels, o.headerLabel(column, fi))
+}
+
+// capitalize title-cases the first letter of a header, which keeps scripts without capitals such as Georgian as they are
+func capitalize(header string) string {
+	first, size := utf8.DecodeRuneInString(header)
+	if first == utf8.RuneError {
+		return header
+	}
+	return string(unicode.ToTitle(first)) + header[size:]
+}
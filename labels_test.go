@@ -0,0 +1,75 @@
+package gexelizer
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLabels_WriteAndRead(t *testing.T) {
+	type address struct {
+		City string `gex:"column:city"`
+	}
+	type row struct {
+		Name    string  `gex:"column:name,label:Full name"`
+		Amount  float64 `gex:"column:amount"`
+		Address address
+	}
+	data := []row{{Name: "John", Amount: 10, Address: address{City: "Tbilisi"}}}
+	translations := Translations{
+		"de": {"name": "Vollständiger Name", "amount": "Betrag", "Address.City": "Stadt"},
+		"ka": {"name": "სახელი", "amount": "თანხა", "address.city": "ქალაქი"},
+	}
+	tests := []struct {
+		name    string
+		opts    []Option
+		headers []string
+	}{
+		{name: "labels", opts: nil, headers: []string{"Full name", "Amount", "Address.city"}},
+		{name: "german", opts: []Option{WithTranslations(translations), WithLanguage("de")}, headers: []string{"Vollständiger Name", "Betrag", "Stadt"}},
+		{name: "georgian", opts: []Option{WithTranslations(translations), WithLanguage("ka")}, headers: []string{"სახელი", "თანხა", "ქალაქი"}},
+		{name: "formatter", opts: []Option{WithHeaderFormatter(func(column, label string) string {
+			return strings.ToUpper(label)
+		})}, headers: []string{"FULL NAME", "AMOUNT", "ADDRESS.CITY"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer, err := WriteExcelToBuffer(data, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			raw := buffer.Bytes()
+			if headers := sheetRows(t, raw)[0]; !reflect.DeepEqual(headers, tt.headers) {
+				t.Fatalf("expected headers %v, got %v", tt.headers, headers)
+			}
+			read, err := ReadExcel[row](bytes.NewReader(raw), tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(read) != 1 || read[0] != data[0] {
+				t.Fatalf("expected %+v, got %+v", data, read)
+			}
+		})
+	}
+	//Any language of the translations is accepted on read
+	buffer, err := WriteExcelToBuffer(data, WithTranslations(translations), WithLanguage("ka"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadExcel[row](buffer, WithTranslations(translations))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 1 || read[0] != data[0] {
+		t.Fatalf("expected %+v, got %+v", data, read)
+	}
+}
+
+func TestLabels_Capitalize(t *testing.T) {
+	for input, expected := range map[string]string{"name": "Name", "ქალაქი": "ქალაქი", "élan": "Élan", "": ""} {
+		if got := capitalize(input); got != expected {
+			t.Fatalf("expected %q, got %q", expected, got)
+		}
+	}
+}
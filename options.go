@@ -1,17 +1,611 @@
 package gexelizer
 
+import (
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Options configures reading and writing. Rows are 1-based sheet row numbers on every entry point.
+// With TrimEmptyRows, leading rows that are empty or have a single cell are skipped,
+// the header is then the first row left at or after HeaderRow and data keeps the same distance from it.
 type Options struct {
 	DataStartRow  uint
 	HeaderRow     uint
 	TrimEmptyRows bool
-	File          ExcelFileWriter
+	// Sheet to read from or write to, the default sheet is used when empty
+	Sheet string
+	// StartColumn is the 1-based first column of the table, 0 means column A
+	StartColumn uint
+	// EndColumn and EndRow bound the table when reading, 0 means unbounded
+	EndColumn uint
+	EndRow    uint
+	// Password opens encrypted files when reading and encrypts the file when writing
+	Password string
+	// StopAt ends reading before the first data row it matches, e.g. a "Total" footer
+	StopAt RowPredicate
+	// SkipRow skips the data rows it matches, e.g. subtotal lines, row numbers in errors are not affected
+	SkipRow RowPredicate
+	// Table is the name of the Excel Table to read, or to format the written rows as.
+	// When reading, its sheet and range replace Sheet and the table bounds
+	Table string
+	// TableStyle is the style of the written table, e.g. "TableStyleMedium2", the Excel default is used when empty
+	TableStyle string
+	// DefinedName is the named range to read, or to define over the written header and rows.
+	// When reading, its sheet and range replace Sheet and the table bounds, a single cell only anchors the table
+	DefinedName string
+	// Recalculate calculates formula cells when reading instead of using their cached values,
+	// formulas that cannot be calculated keep their cached value
+	Recalculate bool
+	// ImageRowHeight is the height in points of written rows holding an Image, 0 keeps the default height
+	ImageRowHeight float64
+	// HeaderStyle styles the written header cells, e.g. bold with a fill and a border
+	HeaderStyle *excelize.Style
+	// AutoFitColumns sizes written columns to their longest value, columns with a width tag keep it
+	AutoFitColumns bool
+	// FreezeHeader freezes the written rows above DataStartRow, so the header stays visible when scrolling
+	FreezeHeader bool
+	// AutoFilter turns on filtering over the written header and rows, tables have their own filter
+	AutoFilter bool
+	// DateTimeFormat and DateFormat are the number formats of written time.Time and Date columns without a numfmt tag,
+	// e.g. "yyyy-mm-dd hh:mm:ss", Excel's default date format is used when empty
+	DateTimeFormat string
+	DateFormat     string
+	// Location is the time zone of the dates in the sheet, Excel dates have none. Written time.Time values are
+	// converted to it and read native dates are in it, UTC when nil
+	Location *time.Location
+	// DataValidation adds the validations of oneof, min and max tags and bool dropdowns to written columns,
+	// from DataStartRow down DataValidationRows rows, or down to the last written row if that is further
+	DataValidation     bool
+	DataValidationRows uint
+	// KeepEmptyColumns keeps written columns without values, which are otherwise removed if they are omitempty or nested
+	KeepEmptyColumns bool
+	// RemoveEmptyColumns removes empty omitempty and nested columns from sheets written with WriteExcelSheet,
+	// which keeps them otherwise as the caller saves the file
+	RemoveEmptyColumns bool
+	// HeaderFormatter formats written headers, the labels it returns are also accepted when reading
+	HeaderFormatter HeaderFormatter
+	// Translations holds header labels per language, headers are written in Language and read in any of them
+	Translations Translations
+	Language     string
+	// InstructionsSheet is the sheet WriteTemplate lists the columns on, "Instructions" by default
+	InstructionsSheet string
+	// ParentCells sets how the parent columns of the rows a slice expands into are written. When reading,
+	// ParentCellsMerge and ParentCellsBlank read rows with a blank primary key as more rows of the value above
+	ParentCells ParentCellMode
+	// OutlineSlices writes a summary row with the parent values above the rows a slice expands into,
+	// and groups those rows one outline level below it. When reading, rows with a blank primary key continue the value above
+	OutlineSlices bool
+	// TotalsLabel is written in the first column of the totals row, added when columns have an aggregate tag.
+	// Columns outside of a slice can only be aggregated if their values are not repeated on every slice row,
+	// with a ChildSheet or blank or merged ParentCells
+	TotalsLabel string
+	// TotalsStyle styles the totals row, aggregate cells keep the number format of their column if it sets none
+	TotalsStyle *excelize.Style
+	// SubtotalGroup is the column whose changing value ends a group with a subtotal row of SubtotalColumns,
+	// aggregated with their aggregate tag or summed
+	SubtotalGroup   string
+	SubtotalColumns []string
+	// SubtotalOutline groups the rows of every subtotal group one outline level below its subtotal row
+	SubtotalOutline bool
+	// MaxRowsPerSheet is the number of rows written below the header of a sheet, subtotal and totals rows included,
+	// before continuing on the next sheet of the series, e.g. "Data (2)". Zero fills every sheet up to the Excel limit
+	MaxRowsPerSheet uint
+	// SheetSeries reads the sheets following the read sheet in a series written with MaxRowsPerSheet as one table
+	SheetSeries bool
+	// ChildSheet is the sheet slice elements are written to and read from, one row per element starting with
+	// the primary key of its value, instead of repeating the parent columns on the sheet of T
+	ChildSheet string
+	// Append continues the sheet of File after its last non-empty row, matching its existing header
+	Append bool
+	File   ExcelFileWriter
+
+	// err keeps the first error of an option, reported on validation
+	err error
+}
+
+// ParentCellMode sets how parent columns are written when a slice expands a value into several rows
+type ParentCellMode int
+
+const (
+	// ParentCellsRepeat repeats the parent values on every row
+	ParentCellsRepeat ParentCellMode = iota
+	// ParentCellsMerge writes the parent values once and merges the parent cells over the rows
+	ParentCellsMerge
+	// ParentCellsBlank writes the parent values once and leaves the parent cells of the other rows blank
+	ParentCellsBlank
+)
+
+// RowPredicate reports whether a row matches, it receives the cell values of the row starting at the first table column
+type RowPredicate func(row []string) bool
+
+// FirstCellEquals matches rows whose first cell equals one of values, ignoring case and surrounding spaces
+func FirstCellEquals(values ...string) RowPredicate {
+	return func(row []string) bool {
+		if len(row) == 0 {
+			return false
+		}
+		first := strings.TrimSpace(row[0])
+		for _, value := range values {
+			if strings.EqualFold(first, strings.TrimSpace(value)) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FirstCellMatches matches rows whose first cell, without surrounding spaces, matches pattern
+func FirstCellMatches(pattern *regexp.Regexp) RowPredicate {
+	return func(row []string) bool {
+		return len(row) > 0 && pattern.MatchString(strings.TrimSpace(row[0]))
+	}
+}
+
+// or combines two predicates, either of which may be nil
+func (p RowPredicate) or(other RowPredicate) RowPredicate {
+	if p == nil {
+		return other
+	}
+	if other == nil {
+		return p
+	}
+	return func(row []string) bool {
+		return p(row) || other(row)
+	}
 }
 
 func DefaultOptions() *Options {
 	return &Options{
-		DataStartRow:  2,
-		HeaderRow:     1,
-		TrimEmptyRows: true,
-		File:          nil,
+		DataStartRow:      2,
+		HeaderRow:         1,
+		TrimEmptyRows:     true,
+		DateTimeFormat:    "yyyy-mm-dd hh:mm:ss",
+		DateFormat:        "yyyy-mm-dd",
+		TotalsLabel:       "Total",
+		InstructionsSheet: "Instructions",
+		File:              nil,
+	}
+}
+
+// Option configures reading or writing, it is implemented by Options and returned by the With* functions.
+// Options are applied in order on top of DefaultOptions, a full Options value replaces everything before it
+// but the errors of earlier options.
+type Option interface {
+	apply(o *Options)
+}
+
+type optionFunc func(o *Options)
+
+func (f optionFunc) apply(o *Options) {
+	f(o)
+}
+
+// apply replaces the options before it with o, keeping the first error of an earlier option.
+// Formats and labels o leaves empty keep their defaults, their With* options clear them
+func (o Options) apply(dst *Options) {
+	defaults := DefaultOptions()
+	if o.DateTimeFormat == "" {
+		o.DateTimeFormat = defaults.DateTimeFormat
+	}
+	if o.DateFormat == "" {
+		o.DateFormat = defaults.DateFormat
+	}
+	if o.TotalsLabel == "" {
+		o.TotalsLabel = defaults.TotalsLabel
+	}
+	if o.InstructionsSheet == "" {
+		o.InstructionsSheet = defaults.InstructionsSheet
+	}
+	err := dst.err
+	*dst = o
+	dst.setErr(err)
+}
+
+// WithHeaderRow sets the 1-based header row, moving the data start row right below it if it is not already lower
+func WithHeaderRow(row uint) Option {
+	return optionFunc(func(o *Options) {
+		o.HeaderRow = row
+		if o.DataStartRow <= row {
+			o.DataStartRow = row + 1
+		}
+	})
+}
+
+// WithDataStartRow sets the 1-based row of the first data row, it has to be below the header row
+func WithDataStartRow(row uint) Option {
+	return optionFunc(func(o *Options) {
+		o.DataStartRow = row
+	})
+}
+
+// WithTrimEmptyRows sets whether empty rows are trimmed when reading
+func WithTrimEmptyRows(trim bool) Option {
+	return optionFunc(func(o *Options) {
+		o.TrimEmptyRows = trim
+	})
+}
+
+// WithSheet sets the sheet to read from or write to
+func WithSheet(sheet string) Option {
+	return optionFunc(func(o *Options) {
+		o.Sheet = sheet
+	})
+}
+
+// WithStartCell anchors the table at cell, e.g. "C5", which becomes the first header cell.
+// Reading and writing start at its column, the header at its row and data right below it
+func WithStartCell(cell string) Option {
+	return optionFunc(func(o *Options) {
+		if err := o.setStartCell(cell); err != nil {
+			o.setErr(err)
+		}
+	})
+}
+
+// WithRange limits the table to the range ref, e.g. "C5:K200", where the first row is the header.
+// It anchors writing like WithStartCell, the end of the range only bounds reading
+func WithRange(ref string) Option {
+	return optionFunc(func(o *Options) {
+		if err := o.setRange(ref); err != nil {
+			o.setErr(err)
+		}
+	})
+}
+
+// WithPassword sets the password encrypted files are read with, and written files are encrypted with
+func WithPassword(password string) Option {
+	return optionFunc(func(o *Options) {
+		o.Password = password
+	})
+}
+
+// WithStopAt ends reading before the first data row matching predicate, it can be given multiple times
+func WithStopAt(predicate RowPredicate) Option {
+	return optionFunc(func(o *Options) {
+		o.StopAt = o.StopAt.or(predicate)
+	})
+}
+
+// WithSkipRow skips data rows matching predicate, it can be given multiple times
+func WithSkipRow(predicate RowPredicate) Option {
+	return optionFunc(func(o *Options) {
+		o.SkipRow = o.SkipRow.or(predicate)
+	})
+}
+
+// WithTable reads the Excel Table called name, or writes the rows as an Excel Table called name
+func WithTable(name string) Option {
+	return optionFunc(func(o *Options) {
+		o.Table = name
+	})
+}
+
+// WithTableStyle sets the style of the written table, e.g. "TableStyleMedium2"
+func WithTableStyle(style string) Option {
+	return optionFunc(func(o *Options) {
+		o.TableStyle = style
+	})
+}
+
+// WithDefinedName reads the named range called name, or defines it over the written header and rows
+func WithDefinedName(name string) Option {
+	return optionFunc(func(o *Options) {
+		o.DefinedName = name
+	})
+}
+
+// WithRecalculate sets whether formula cells are calculated when reading instead of using their cached values
+func WithRecalculate(recalculate bool) Option {
+	return optionFunc(func(o *Options) {
+		o.Recalculate = recalculate
+	})
+}
+
+// WithImageRowHeight sets the height in points of written rows holding an Image, images are scaled to their cell
+func WithImageRowHeight(height float64) Option {
+	return optionFunc(func(o *Options) {
+		o.ImageRowHeight = height
+	})
+}
+
+// WithHeaderStyle sets the style of the written header cells
+func WithHeaderStyle(style *excelize.Style) Option {
+	return optionFunc(func(o *Options) {
+		o.HeaderStyle = style
+	})
+}
+
+// WithAutoFitColumns sets whether written columns are sized to their longest value
+func WithAutoFitColumns(autoFit bool) Option {
+	return optionFunc(func(o *Options) {
+		o.AutoFitColumns = autoFit
+	})
+}
+
+// WithFreezeHeader sets whether the written rows above the data start row are frozen
+func WithFreezeHeader(freeze bool) Option {
+	return optionFunc(func(o *Options) {
+		o.FreezeHeader = freeze
+	})
+}
+
+// WithAutoFilter sets whether filtering is turned on over the written header and rows
+func WithAutoFilter(autoFilter bool) Option {
+	return optionFunc(func(o *Options) {
+		o.AutoFilter = autoFilter
+	})
+}
+
+// WithDateTimeFormat sets the number format of written time.Time columns, e.g. "dd/mm/yyyy hh:mm"
+func WithDateTimeFormat(format string) Option {
+	return optionFunc(func(o *Options) {
+		o.DateTimeFormat = format
+	})
+}
+
+// WithLocation sets the time zone written time.Time values are converted to and read native dates are in, UTC by default
+func WithLocation(location *time.Location) Option {
+	return optionFunc(func(o *Options) {
+		o.Location = location
+	})
+}
+
+// location returns the time zone of the dates in the sheet, see Location
+func (o Options) location() *time.Location {
+	if o.Location == nil {
+		return time.UTC
+	}
+	return o.Location
+}
+
+// WithDateFormat sets the number format of written Date columns, e.g. "dd/mm/yyyy"
+func WithDateFormat(format string) Option {
+	return optionFunc(func(o *Options) {
+		o.DateFormat = format
+	})
+}
+
+// WithDataValidation adds data validations to written columns for rows rows from the data start row,
+// so blank templates can be filled in, 0 validates the written rows only
+func WithDataValidation(rows uint) Option {
+	return optionFunc(func(o *Options) {
+		o.DataValidation = true
+		o.DataValidationRows = rows
+	})
+}
+
+// WithKeepEmptyColumns sets whether written columns without values are kept
+func WithKeepEmptyColumns(keep bool) Option {
+	return optionFunc(func(o *Options) {
+		o.KeepEmptyColumns = keep
+	})
+}
+
+// WithRemoveEmptyColumns sets whether WriteExcelSheet removes written columns without values, it keeps them by default
+func WithRemoveEmptyColumns(remove bool) Option {
+	return optionFunc(func(o *Options) {
+		o.RemoveEmptyColumns = remove
+	})
+}
+
+// WithHeaderFormatter sets the function formatting written headers
+func WithHeaderFormatter(formatter HeaderFormatter) Option {
+	return optionFunc(func(o *Options) {
+		o.HeaderFormatter = formatter
+	})
+}
+
+// WithTranslations sets the header labels per language, headers in any of the languages are accepted when reading
+func WithTranslations(translations Translations) Option {
+	return optionFunc(func(o *Options) {
+		o.Translations = translations
+	})
+}
+
+// WithLanguage sets the language of the translations headers are written in
+func WithLanguage(language string) Option {
+	return optionFunc(func(o *Options) {
+		o.Language = language
+	})
+}
+
+// WithParentCells sets how parent columns are written when a slice expands a value into several rows.
+// Readers accept every mode, a row with a blank primary key continues the previous value
+func WithParentCells(mode ParentCellMode) Option {
+	return optionFunc(func(o *Options) {
+		o.ParentCells = mode
+	})
+}
+
+// WithOutlineSlices sets whether the rows a slice expands into are grouped below a summary row of the parent values.
+// Readers skip summary rows as they hold no slice values
+func WithOutlineSlices(outline bool) Option {
+	return optionFunc(func(o *Options) {
+		o.OutlineSlices = outline
+	})
+}
+
+// WithInstructionsSheet sets the sheet WriteTemplate lists the columns on
+func WithInstructionsSheet(sheet string) Option {
+	return optionFunc(func(o *Options) {
+		o.InstructionsSheet = sheet
+	})
+}
+
+// WithTotalsLabel sets the label of the totals row, "Total" by default
+func WithTotalsLabel(label string) Option {
+	return optionFunc(func(o *Options) {
+		o.TotalsLabel = label
+	})
+}
+
+// WithTotalsStyle sets the style of the totals row, e.g. bold with a top border
+func WithTotalsStyle(style *excelize.Style) Option {
+	return optionFunc(func(o *Options) {
+		o.TotalsStyle = style
+	})
+}
+
+// WithSubtotals sets the column grouping written rows and the columns aggregated in a subtotal row below every group.
+// Columns are aggregated with their aggregate tag, or summed if they have none, and rows are grouped as they are written.
+// Like totals, columns outside of a slice need a ChildSheet or blank or merged ParentCells.
+// Readers skip subtotal rows of columns with an aggregate tag, and of the columns set here, or every number and date column if none are
+func WithSubtotals(group string, columns ...string) Option {
+	return optionFunc(func(o *Options) {
+		o.SubtotalGroup = group
+		o.SubtotalColumns = columns
+	})
+}
+
+// WithSubtotalOutline sets whether the rows of every subtotal group are grouped one outline level below its subtotal row
+func WithSubtotalOutline(outline bool) Option {
+	return optionFunc(func(o *Options) {
+		o.SubtotalOutline = outline
+	})
+}
+
+// WithMaxRowsPerSheet sets the number of rows written below the header of a sheet before continuing on the next sheet
+// of the series, "Data (2)", "Data (3)" and so on, each starting with the headers. A value of T is never split across sheets
+func WithMaxRowsPerSheet(rows uint) Option {
+	return optionFunc(func(o *Options) {
+		o.MaxRowsPerSheet = rows
+	})
+}
+
+// WithSheetSeries sets whether the sheets following the read sheet in a series, "Data (2)", "Data (3)" and so on,
+// are read after it as one table
+func WithSheetSeries(series bool) Option {
+	return optionFunc(func(o *Options) {
+		o.SheetSeries = series
+	})
+}
+
+// WithChildSheet sets the sheet slice elements are written to and read from instead of the sheet of T.
+// Every child row starts with the primary key of its value, and readers join the child rows to the values by it,
+// failing on child rows of keys no value has. The child sheet rolls over like the sheet of T, see WithMaxRowsPerSheet
+func WithChildSheet(sheet string) Option {
+	return optionFunc(func(o *Options) {
+		o.ChildSheet = sheet
+	})
+}
+
+// WithAppend sets whether writing continues after the last non-empty row of the sheet instead of starting at the header row.
+// The existing header is matched by column names, aliases and labels, and columns missing from it are added to its end
+func WithAppend(appendRows bool) Option {
+	return optionFunc(func(o *Options) {
+		o.Append = appendRows
+	})
+}
+
+// WithFile sets the file TypeWriter writes into, instead of creating a new one
+func WithFile(file ExcelFileWriter) Option {
+	return optionFunc(func(o *Options) {
+		o.File = file
+	})
+}
+
+// newOptions applies opts on top of the defaults and validates the result
+func newOptions(opts ...Option) (Options, error) {
+	options := *DefaultOptions()
+	for _, opt := range opts {
+		if opt != nil {
+			opt.apply(&options)
+		}
+	}
+	return options, options.validate()
+}
+
+// setStartCell anchors the options at cell, with the header on its row
+func (o *Options) setStartCell(cell string) error {
+	column, row, err := excelize.CellNameToCoordinates(strings.ReplaceAll(cell, "$", ""))
+	if err != nil {
+		return fmt.Errorf("invalid start cell %s: %w", cell, err)
+	}
+	o.StartColumn = uint(column)
+	o.HeaderRow = uint(row)
+	o.DataStartRow = uint(row) + 1
+	return nil
+}
+
+// setRange bounds the options to ref, with the header on its first row
+func (o *Options) setRange(ref string) error {
+	startColumn, startRow, endColumn, endRow, err := parseRange(ref)
+	if err != nil {
+		return err
+	}
+	o.StartColumn = uint(startColumn)
+	o.HeaderRow = uint(startRow)
+	o.DataStartRow = uint(startRow) + 1
+	o.EndColumn = uint(endColumn)
+	o.EndRow = uint(endRow)
+	return nil
+}
+
+func (o *Options) setErr(err error) {
+	if o.err == nil {
+		o.err = err
+	}
+}
+
+// startColumn returns the 1-based first column of the table
+func (o Options) startColumn() int {
+	if o.StartColumn == 0 {
+		return 1
+	}
+	return int(o.StartColumn)
+}
+
+// parseRange parses a range reference such as "C5:K200" or "$C$5:$K$200" into 1-based coordinates
+func parseRange(ref string) (startColumn, startRow, endColumn, endRow int, err error) {
+	cells := strings.Split(strings.ReplaceAll(ref, "$", ""), ":")
+	if len(cells) != 2 {
+		return 0, 0, 0, 0, fmt.Errorf("invalid range %s: expected two cells separated by a colon", ref)
+	}
+	if startColumn, startRow, err = excelize.CellNameToCoordinates(cells[0]); err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("invalid range %s: %w", ref, err)
+	}
+	if endColumn, endRow, err = excelize.CellNameToCoordinates(cells[1]); err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("invalid range %s: %w", ref, err)
+	}
+	if endColumn < startColumn || endRow < startRow {
+		return 0, 0, 0, 0, fmt.Errorf("invalid range %s: end cell is before start cell", ref)
+	}
+	return startColumn, startRow, endColumn, endRow, nil
+}
+
+func (o Options) validate() error {
+	if o.err != nil {
+		return fmt.Errorf("invalid options: %w", o.err)
+	}
+	if o.HeaderRow == 0 {
+		return fmt.Errorf("invalid options: header row must be at least 1, rows are 1-based")
+	}
+	if o.DataStartRow <= o.HeaderRow {
+		return fmt.Errorf("invalid options: data start row (%d) must be greater than header row (%d)", o.DataStartRow, o.HeaderRow)
+	}
+	if o.EndColumn != 0 && o.EndColumn < uint(o.startColumn()) {
+		return fmt.Errorf("invalid options: end column (%d) must not be before start column (%d)", o.EndColumn, o.startColumn())
+	}
+	if o.ChildSheet != "" && (o.Table != "" || o.DefinedName != "") {
+		return fmt.Errorf("invalid options: a child sheet cannot be read with a table or a defined name")
+	}
+	if o.SheetSeries && (o.Table != "" || o.DefinedName != "") {
+		return fmt.Errorf("invalid options: a sheet series cannot be read from a table or a defined name")
+	}
+	if o.MaxRowsPerSheet > excelize.TotalRows {
+		return fmt.Errorf("invalid options: max rows per sheet (%d) must not exceed %d", o.MaxRowsPerSheet, excelize.TotalRows)
+	}
+	if o.ParentCells < ParentCellsRepeat || o.ParentCells > ParentCellsBlank {
+		return fmt.Errorf("invalid options: unknown parent cell mode %d", o.ParentCells)
+	}
+	if o.ImageRowHeight < 0 || o.ImageRowHeight > excelize.MaxRowHeight {
+		return fmt.Errorf("invalid options: image row height (%v) must be between 0 and %d", o.ImageRowHeight, excelize.MaxRowHeight)
+	}
+	if o.EndRow != 0 && o.EndRow < o.HeaderRow {
+		return fmt.Errorf("invalid options: end row (%d) must not be before header row (%d)", o.EndRow, o.HeaderRow)
 	}
+	return nil
 }
@@ -0,0 +1,197 @@
+package gexelizer
+
+import (
+	"bytes"
+	"github.com/xuri/excelize/v2"
+	"testing"
+)
+
+func TestOptions_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{name: "defaults", opts: nil},
+		{name: "header row moves data start", opts: []Option{WithHeaderRow(3)}},
+		{name: "data start after header", opts: []Option{WithHeaderRow(3), WithDataStartRow(5)}},
+		{name: "data start on header", opts: []Option{WithHeaderRow(3), WithDataStartRow(3)}, wantErr: true},
+		{name: "zero header row", opts: []Option{WithHeaderRow(0)}, wantErr: true},
+		{name: "zero struct", opts: []Option{Options{}}, wantErr: true},
+		{name: "struct", opts: []Option{Options{HeaderRow: 2, DataStartRow: 4}}},
+		{name: "struct after invalid option", opts: []Option{WithStartCell("bogus!"), *DefaultOptions()}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newOptions(tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error: %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+	options, err := newOptions(Options{HeaderRow: 1, DataStartRow: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if defaults := DefaultOptions(); options.DateTimeFormat != defaults.DateTimeFormat || options.DateFormat != defaults.DateFormat {
+		t.Fatalf("expected a struct without formats to keep the default formats, got %q and %q", options.DateTimeFormat, options.DateFormat)
+	}
+	buffer, err := WriteExcelToBuffer([]struct{ Name string }{{Name: "John"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTypeReader[struct{ Name string }](buffer, WithDataStartRow(1)); err == nil {
+		t.Fatal("expected the reader to reject invalid options")
+	}
+	if _, err := NewTypeWriter[struct{ Name string }](WithHeaderRow(0)); err == nil {
+		t.Fatal("expected the writer to reject invalid options")
+	}
+}
+
+func TestOptions_SameSemanticsOnEveryEntryPoint(t *testing.T) {
+	type row struct {
+		Name string `gex:"column:name"`
+		Age  int    `gex:"column:age"`
+	}
+	data := []row{{Name: "John", Age: 30}, {Name: "Jane", Age: 25}}
+	opts := []Option{WithHeaderRow(3), WithDataStartRow(5), WithSheet("Data")}
+	buffer, err := WriteExcelToBuffer(data, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := buffer.Bytes()
+	rows := sheetRows(t, raw)
+	if len(rows) != 6 || rows[2][0] != "Name" || rows[4][0] != "John" {
+		t.Fatalf("unexpected layout %v", rows)
+	}
+
+	read, err := ReadExcel[row](bytes.NewReader(raw), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := NewTypeReader[row](bytes.NewReader(raw), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readWithReader, err := reader.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	//Reading twice with the same options proves they were not mutated
+	readAgain, err := ReadExcel[row](bytes.NewReader(raw), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range [][]row{read, readWithReader, readAgain} {
+		if len(result) != len(data) {
+			t.Fatalf("expected %d rows, got %+v", len(data), result)
+		}
+		for i := range data {
+			if result[i] != data[i] {
+				t.Fatalf("expected %+v, got %+v", data[i], result[i])
+			}
+		}
+	}
+}
+
+func TestOptions_WriteExcelSheetDoesNotMutate(t *testing.T) {
+	type row struct {
+		Name string
+		Note string `gex:"omitempty"`
+	}
+	options := Options{HeaderRow: 1, DataStartRow: 2, TrimEmptyRows: true}
+	opts := []Option{options}
+	file, err := WriteExcelSheet(nil, "first", []row{{Name: "John"}}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WriteExcelSheet(file, "second", []row{{Name: "Jane"}}, opts...); err != nil {
+		t.Fatal(err)
+	}
+	if opts[0].(Options).File != nil || opts[0].(Options).Sheet != "" {
+		t.Fatal("caller's options should not be modified")
+	}
+	if _, err := WriteExcelSheet(file, "third", []row{{Name: "Jim"}}); err != nil {
+		t.Fatal(err)
+	}
+	if header, _ := file.GetBaseFile().GetCellValue("third", "B1"); header != "Note" {
+		t.Fatalf("expected the empty column to be kept, got header %q", header)
+	}
+	//Full Options values keep empty columns like functional options, unless asked to remove them
+	for sheet, header := range map[string]string{"first": "Note", "second": "Note"} {
+		if value, _ := file.GetBaseFile().GetCellValue(sheet, "B1"); value != header {
+			t.Fatalf("expected the empty column of %s to be kept, got header %q", sheet, value)
+		}
+	}
+	if _, err := WriteExcelSheet(file, "fourth", []row{{Name: "Joe"}}, *DefaultOptions()); err != nil {
+		t.Fatal(err)
+	}
+	if header, _ := file.GetBaseFile().GetCellValue("fourth", "B1"); header != "Note" {
+		t.Fatalf("expected the empty column to be kept with the default options, got header %q", header)
+	}
+	if _, err := WriteExcelSheet(file, "fifth", []row{{Name: "Jo"}}, WithRemoveEmptyColumns(true)); err != nil {
+		t.Fatal(err)
+	}
+	if header, _ := file.GetBaseFile().GetCellValue("fifth", "B1"); header != "" {
+		t.Fatalf("expected the empty column to be removed, got header %q", header)
+	}
+	buffer, err := file.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ReadExcel[row](buffer, WithSheet("second"), WithTrimEmptyRows(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second) != 1 || second[0].Name != "Jane" {
+		t.Fatalf("unexpected rows %+v", second)
+	}
+}
+
+func TestOptions_StartCellAndRange(t *testing.T) {
+	type row struct {
+		Name   string  `gex:"column:name"`
+		Amount float64 `gex:"column:amount"`
+	}
+	data := []row{{Name: "John", Amount: 10}, {Name: "Jane", Amount: 20}}
+	buffer, err := WriteExcelToBuffer(data, WithStartCell("C5"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header, _ := file.GetCellValue("Sheet1", "C5"); header != "Name" {
+		t.Fatalf("expected the header at C5, got %q", header)
+	}
+	if value, _ := file.GetCellValue("Sheet1", "D7"); value != "20" {
+		t.Fatalf("expected the last amount at D7, got %q", value)
+	}
+	//Surround the table with labels, a footer and a side note
+	_ = file.SetCellValue("Sheet1", "A1", "Monthly report")
+	_ = file.SetCellValue("Sheet1", "A6", "label")
+	_ = file.SetCellValue("Sheet1", "A7", "label")
+	_ = file.SetCellValue("Sheet1", "F6", "side note")
+	_ = file.SetSheetRow("Sheet1", "C9", &[]any{"Generated by", "reports"})
+	surrounded, err := file.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := ReadExcel[row](bytes.NewReader(surrounded.Bytes()), WithRange("C5:D7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 2 || read[0] != data[0] || read[1] != data[1] {
+		t.Fatalf("expected %+v, got %+v", data, read)
+	}
+	if _, err := ReadExcel[row](bytes.NewReader(surrounded.Bytes()), WithStartCell("C5")); err == nil {
+		t.Fatal("expected the footer to be read without a bounded range")
+	}
+	for _, opt := range []Option{WithStartCell("5C"), WithRange("C5"), WithRange("D7:C5")} {
+		if _, err := newOptions(opt); err == nil {
+			t.Fatal("expected an invalid cell reference to be rejected")
+		}
+	}
+}
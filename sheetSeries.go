@@ -0,0 +1,148 @@
+package gexelizer
+
+import (
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"strings"
+)
+
+// maxSheetNameLength is the longest sheet name Excel accepts
+const maxSheetNameLength = 31
+
+// sheetSeries tracks the sheets a writer rolls over to, see WithMaxRowsPerSheet
+type sheetSeries struct {
+	// sheet, table and definedName are the names of the first sheet, numbered on the following ones
+	sheet       string
+	table       string
+	definedName string
+	number      int
+}
+
+// seriesSheetName returns the name of sheet number of the series starting at base, e.g. "Data (2)",
+// cutting base so the name fits the sheet name limit
+func seriesSheetName(base string, number int) string {
+	if number <= 1 {
+		return base
+	}
+	suffix := fmt.Sprintf(" (%d)", number)
+	runes := []rune(base)
+	if len(runes)+len(suffix) > maxSheetNameLength {
+		runes = runes[:maxSheetNameLength-len(suffix)]
+	}
+	return string(runes) + suffix
+}
+
+// lastRow returns the last row the writer may write on a sheet, the totals row included
+func (w *TypeWriter[T]) lastRow() uint {
+	last := uint(excelize.TotalRows)
+	if w.options.MaxRowsPerSheet > 0 && w.options.DataStartRow+w.options.MaxRowsPerSheet-1 < last {
+		last = w.options.DataStartRow + w.options.MaxRowsPerSheet - 1
+	}
+	return last
+}
+
+// makeRoom rolls over to the next sheet of the series if the rows of a value of T do not fit the current one,
+// keeping room for the subtotal and totals rows written after them
+func (w *TypeWriter[T]) makeRoom(rows int) error {
+	needed := uint(rows)
+	if w.subtotals != nil {
+		needed++
+	}
+	if w.hasTotals() {
+		needed++
+	}
+	if w.nextRowToWrite+needed-1 <= w.lastRow() {
+		return nil
+	}
+	if w.nextRowToWrite == w.firstDataRow {
+		return fmt.Errorf("%d rows of a single value do not fit in %d rows per sheet", rows, w.lastRow()-w.options.DataStartRow+1)
+	}
+	return w.rollOver()
+}
+
+// hasTotals reports whether a totals row is written below the rows
+func (w *TypeWriter[T]) hasTotals() bool {
+	for _, header := range w.headers {
+		if w.typeInfo.nameToField[header].aggregate != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// rollOver finalizes the current sheet and continues on the next sheet of the series, starting with the headers.
+// Tables and defined names are numbered like the sheets, e.g. "Sales_2", as their names are unique in a workbook.
+// An open subtotal group continues on the next sheet, its subtotal row aggregates its rows on both
+func (w *TypeWriter[T]) rollOver() error {
+	if err := w.finalizeSheet(); err != nil {
+		return err
+	}
+	if err := w.continueGroup(); err != nil {
+		return err
+	}
+	if w.series == nil {
+		w.series = &sheetSeries{sheet: w.sheet, table: w.options.Table, definedName: w.options.DefinedName, number: 1}
+	}
+	w.series.number++
+	if err := w.file.SetDefaultSheet(seriesSheetName(w.series.sheet, w.series.number)); err != nil {
+		return err
+	}
+	w.sheet = w.file.GetDefaultSheet()
+	if w.series.table != "" {
+		w.options.Table = fmt.Sprintf("%s_%d", w.series.table, w.series.number)
+	}
+	if w.series.definedName != "" {
+		w.options.DefinedName = fmt.Sprintf("%s_%d", w.series.definedName, w.series.number)
+	}
+	w.finalized = false
+	w.visibleColumns = nil
+	w.columnOffsets = nil
+	w.sheetWidth = 0
+	w.columnLengths = make([]int, len(w.headers))
+	w.nextRowToWrite = w.options.DataStartRow
+	w.firstDataRow = w.options.DataStartRow
+	if w.subtotals != nil {
+		w.subtotals.rows = nil
+		w.subtotals.firstRow = w.nextRowToWrite
+	}
+	return w.writeHeaders()
+}
+
+// nextSheet moves the reader to the next sheet of the series it reads, false if there is none
+func (t *TypeReader[T]) nextSheet() (bool, error) {
+	if t.series == nil {
+		t.series = &sheetSeries{sheet: t.sheet, number: 1}
+	}
+	next := seriesSheetName(t.series.sheet, t.series.number+1)
+	if _, err := t.file.GetSheetRows(next); err != nil {
+		return false, nil
+	}
+	t.series.number++
+	t.options.Sheet = next
+	t.sheet = next
+	t.previousRow = nil
+	headerIndex, err := t.loadRows()
+	if err != nil {
+		return false, fmt.Errorf("sheet %s: %w", next, err)
+	}
+	//The type is analyzed once, columns are only mapped again if empty columns left out differ between sheets
+	if !t.sameHeaders(t.rows[headerIndex]) {
+		if err := t.mapHeaders(t.rows[headerIndex]); err != nil {
+			return false, fmt.Errorf("sheet %s: %w", next, err)
+		}
+	}
+	return true, t.readSheetValues(headerIndex)
+}
+
+// sameHeaders reports whether headerRow holds the headers the columns are mapped to
+func (t *TypeReader[T]) sameHeaders(headerRow []string) bool {
+	if len(headerRow) != len(t.headers) {
+		return false
+	}
+	for i, header := range headerRow {
+		if strings.TrimSpace(strings.ToLower(header)) != t.headers[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,198 @@
+package gexelizer
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"reflect"
+	"testing"
+)
+
+func TestSheetSeries_WriteAndRead(t *testing.T) {
+	type row struct {
+		Name   string `gex:"column:name"`
+		Amount int    `gex:"column:amount"`
+	}
+	data := make([]row, 7)
+	for i := range data {
+		data[i] = row{Name: fmt.Sprintf("row %d", i+1), Amount: i + 1}
+	}
+	buffer, err := WriteExcelToBuffer(data, WithSheet("Data"), WithMaxRowsPerSheet(3), WithTable("Sales"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sheets := file.GetSheetList(); !reflect.DeepEqual(sheets, []string{"Data", "Data (2)", "Data (3)"}) {
+		t.Fatalf("unexpected sheets %v", sheets)
+	}
+	for sheet, expectedRows := range map[string]int{"Data": 4, "Data (2)": 4, "Data (3)": 2} {
+		rows, err := file.GetRows(sheet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != expectedRows || rows[0][0] != "Name" {
+			t.Fatalf("expected %d rows starting with the headers on %s, got %v", expectedRows, sheet, rows)
+		}
+	}
+	if tables, _ := file.GetTables("Data (3)"); len(tables) != 1 || tables[0].Name != "Sales_3" || tables[0].Range != "A1:B2" {
+		t.Fatalf("expected a numbered table on every sheet, got %+v", tables)
+	}
+
+	read, err := ReadExcel[row](bytes.NewReader(buffer.Bytes()), WithSheet("Data"), WithSheetSeries(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, data) {
+		t.Fatalf("expected %+v, got %+v", data, read)
+	}
+	first, err := ReadExcel[row](bytes.NewReader(buffer.Bytes()), WithSheet("Data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 3 {
+		t.Fatalf("expected only the first sheet without a series, got %+v", first)
+	}
+	if _, err := newOptions(WithSheetSeries(true), WithTable("Sales")); err == nil {
+		t.Fatal("expected a series read from a table to be rejected")
+	}
+}
+
+func TestSheetSeries_ValuesAreNotSplit(t *testing.T) {
+	type item struct {
+		SKU string `gex:"column:sku"`
+	}
+	type order struct {
+		ID    int    `gex:"column:id,primary"`
+		Items []item `gex:"column:items"`
+		Total int    `gex:"column:total,sum"`
+	}
+	data := []order{
+		{ID: 1, Items: []item{{"a"}, {"b"}}, Total: 10},
+		{ID: 2, Items: []item{{"c"}, {"d"}}, Total: 20},
+		{ID: 3, Items: []item{{"e"}}, Total: 30},
+	}
+	//Two rows of an order and the totals row fill a sheet, the total of an order is written on its first row only
+	buffer, err := WriteExcelToBuffer(data, WithMaxRowsPerSheet(3), WithParentCells(ParentCellsBlank))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sheets := file.GetSheetList(); len(sheets) != 3 {
+		t.Fatalf("expected an order per sheet, got %v", sheets)
+	}
+	if formula, _ := file.GetCellFormula("Sheet1 (2)", "C4"); formula != "SUBTOTAL(109,C2:C3)" {
+		t.Fatalf("expected the totals of every sheet, got %q", formula)
+	}
+	if value, err := file.CalcCellValue("Sheet1 (2)", "C4"); err != nil || value != "20" {
+		t.Fatalf("expected the total of the second order, got %q (%v)", value, err)
+	}
+	read, err := ReadExcel[order](bytes.NewReader(buffer.Bytes()), WithSheetSeries(true), WithTrimEmptyRows(false),
+		WithParentCells(ParentCellsBlank))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, data) {
+		t.Fatalf("expected %+v, got %+v", data, read)
+	}
+
+	if _, err := WriteExcelToBuffer(data, WithMaxRowsPerSheet(2), WithParentCells(ParentCellsBlank)); err == nil {
+		t.Fatal("expected a value larger than a sheet to be rejected")
+	}
+	name := seriesSheetName("A very long sheet name for sales", 12)
+	if len(name) != maxSheetNameLength || name != "A very long sheet name for (12)" {
+		t.Fatalf("expected the name cut to the sheet name limit, got %q", name)
+	}
+}
+
+func TestSheetSeries_SubtotalGroupContinues(t *testing.T) {
+	type sale struct {
+		Region string  `gex:"column:region"`
+		Note   string  `gex:"column:note,omitempty"`
+		Amount float64 `gex:"column:amount"`
+	}
+	data := []sale{
+		{Region: "East", Note: "first", Amount: 100},
+		{Region: "East", Amount: 50},
+		{Region: "East", Amount: 20},
+		{Region: "West", Amount: 70},
+	}
+	buffer, err := WriteExcelToBuffer(data, WithSheet("Sales Data"), WithMaxRowsPerSheet(3), WithSubtotals("region", "amount"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := file.GetRows("Sales Data (2)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	//The East group continues on the second sheet, whose empty note column is removed
+	expectedRows := [][]string{
+		{"Region", "Amount"},
+		{"East", "20"},
+		{"East Total", ""},
+	}
+	if !reflect.DeepEqual(rows, expectedRows) {
+		t.Fatalf("expected %v, got %v", expectedRows, rows)
+	}
+	if formula, _ := file.GetCellFormula("Sales Data (2)", "B3"); formula != "SUBTOTAL(109,'Sales Data'!C2:C3,B2:B2)" {
+		t.Fatalf("expected the subtotal over the rows of both sheets, got %q", formula)
+	}
+	if value, err := file.CalcCellValue("Sales Data (2)", "B3"); err != nil || value != "170" {
+		t.Fatalf("expected the East subtotal to calculate to 170, got %q (%v)", value, err)
+	}
+	if rows, _ := file.GetRows("Sales Data"); len(rows) != 3 {
+		t.Fatalf("expected no subtotal row at the end of the first sheet, got %v", rows)
+	}
+	read, err := ReadExcel[sale](bytes.NewReader(buffer.Bytes()), WithSheet("Sales Data"), WithSheetSeries(true), WithSubtotals("region", "amount"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, data) {
+		t.Fatalf("expected %+v, got %+v", data, read)
+	}
+}
+
+func TestSheetSeries_FullSheetWithoutMaxRows(t *testing.T) {
+	type row struct {
+		Name string `gex:"column:name"`
+	}
+	file := excelize.NewFile()
+	if err := file.SetSheetRow("Sheet1", "A1", &[]any{"Name"}); err != nil {
+		t.Fatal(err)
+	}
+	last := fmt.Sprintf("A%d", excelize.TotalRows-1)
+	if err := file.SetCellValue("Sheet1", last, "almost full"); err != nil {
+		t.Fatal(err)
+	}
+	data := []row{{"a"}, {"b"}, {"c"}}
+	//Zero rows per sheet fills the sheet up to the Excel limit and continues on the next
+	writer, err := NewTypeWriter[row](WithFile(&excelFile{file: file}), WithAppend(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.finalize(); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := file.GetCellValue("Sheet1", fmt.Sprintf("A%d", excelize.TotalRows)); value != "a" {
+		t.Fatalf("expected the last row of the sheet to be written, got %q", value)
+	}
+	rows, err := file.GetRows("Sheet1 (2)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := [][]string{{"Name"}, {"b"}, {"c"}}; !reflect.DeepEqual(rows, expected) {
+		t.Fatalf("expected %v, got %v", expected, rows)
+	}
+}
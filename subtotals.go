@@ -0,0 +1,194 @@
+package gexelizer
+
+import (
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"strings"
+)
+
+// subtotalGroups tracks the group of the written rows, see WithSubtotals
+type subtotalGroups struct {
+	// column is the header index of the group column
+	column int
+	// aggregates holds the aggregate of every header, empty for headers without a subtotal
+	aggregates []string
+	// key is the group value of the current group, which starts at firstRow
+	key      string
+	firstRow uint
+	started  bool
+	// rows holds the written subtotal rows, styled once the columns are final
+	rows []uint
+	// previousRanges holds the ranges of every header the current group covers on earlier sheets of a series,
+	// as a group continues on the next sheet
+	previousRanges [][]string
+}
+
+// analyzeSubtotals resolves the group and subtotal columns of the options against the headers
+func (w *TypeWriter[T]) analyzeSubtotals() error {
+	if w.options.SubtotalGroup == "" {
+		if len(w.options.SubtotalColumns) > 0 {
+			return fmt.Errorf("subtotal columns need a group column")
+		}
+		return nil
+	}
+	group, ok := w.headerIndex(w.options.SubtotalGroup)
+	if !ok {
+		return fmt.Errorf("subtotal group column %s does not exist", w.options.SubtotalGroup)
+	}
+	if len(w.options.SubtotalColumns) == 0 {
+		return fmt.Errorf("subtotals of group column %s need at least one column", w.options.SubtotalGroup)
+	}
+	groups := &subtotalGroups{column: group, aggregates: make([]string, len(w.headers))}
+	for _, col := range w.options.SubtotalColumns {
+		i, ok := w.headerIndex(col)
+		if !ok {
+			return fmt.Errorf("subtotal column %s does not exist", col)
+		}
+		fi := w.typeInfo.nameToField[w.headers[i]]
+		if w.repeatsParentValues() && w.parentColumns[i] {
+			return fmt.Errorf("subtotal column %s would count its value once per slice row, "+
+				"write the slice to a child sheet or with blank or merged parent cells", col)
+		}
+		aggregate := fi.aggregate
+		if aggregate == "" {
+			aggregate = aggregateSum
+		}
+		if !aggregateSupported(aggregate, fi.valueType) {
+			return fmt.Errorf("aggregate %s is not supported by subtotal column %s", aggregate, col)
+		}
+		groups.aggregates[i] = aggregate
+	}
+	w.subtotals = groups
+	return nil
+}
+
+// headerIndex returns the index of the header of column, matched by name or alias
+func (w *TypeWriter[T]) headerIndex(column string) (int, bool) {
+	fi, exists := w.typeInfo.nameToField[strings.ToLower(strings.TrimSpace(column))]
+	if !exists {
+		return 0, false
+	}
+	for i, header := range w.headers {
+		if strings.EqualFold(w.typeInfo.nameToField[header].name, fi.name) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// startGroup writes the subtotal row of the current group if row starts a new one
+func (w *TypeWriter[T]) startGroup(row []any) error {
+	if w.subtotals == nil {
+		return nil
+	}
+	key := ""
+	if value := row[w.subtotals.column]; value != nil {
+		key = fmt.Sprint(value)
+	}
+	if w.subtotals.started && key == w.subtotals.key {
+		return nil
+	}
+	if err := w.closeGroup(); err != nil {
+		return err
+	}
+	w.subtotals.key = key
+	w.subtotals.firstRow = w.nextRowToWrite
+	w.subtotals.started = true
+	return nil
+}
+
+// closeGroup writes the subtotal row of the current group below its rows: the group value with the totals label
+// in the group column and SUBTOTAL formulas over the group rows in the subtotal columns
+func (w *TypeWriter[T]) closeGroup() error {
+	if w.subtotals == nil || !w.subtotals.started {
+		return nil
+	}
+	w.subtotals.started = false
+	row := w.nextRowToWrite
+	values := make([]any, len(w.headers))
+	values[w.subtotals.column] = strings.TrimSpace(w.subtotals.key + " " + w.options.TotalsLabel)
+	for i, aggregate := range w.subtotals.aggregates {
+		if aggregate == "" {
+			continue
+		}
+		column, err := excelize.ColumnNumberToName(w.headerColumn(i))
+		if err != nil {
+			return err
+		}
+		var ranges []string
+		if w.subtotals.previousRanges != nil {
+			ranges = w.subtotals.previousRanges[i]
+		}
+		if row > w.subtotals.firstRow {
+			ranges = append(ranges, columnRange(column, w.subtotals.firstRow, row-1))
+		}
+		values[i] = subtotalFormula(aggregate, ranges...)
+	}
+	w.subtotals.previousRanges = nil
+	if err := w.file.SetRow(uint(w.options.startColumn()), row, w.sheetRow(values)); err != nil {
+		return err
+	}
+	w.trackLength(w.subtotals.column, values[w.subtotals.column])
+	w.subtotals.rows = append(w.subtotals.rows, row)
+	w.nextRowToWrite++
+	return nil
+}
+
+// continueGroup keeps the current group open on the next sheet of the series, adding the ranges of its rows
+// on the finalized sheet to the previous ranges. It runs between finalizing the sheet and moving on
+func (w *TypeWriter[T]) continueGroup() error {
+	if w.subtotals == nil || !w.subtotals.started || w.nextRowToWrite <= w.subtotals.firstRow {
+		return nil
+	}
+	if w.subtotals.previousRanges == nil {
+		w.subtotals.previousRanges = make([][]string, len(w.headers))
+	}
+	for k, i := range w.visibleColumns {
+		if w.subtotals.aggregates[i] == "" {
+			continue
+		}
+		column, err := excelize.ColumnNumberToName(w.columnNumber(k))
+		if err != nil {
+			return err
+		}
+		ref := sheetReference(w.sheet, columnRange(column, w.subtotals.firstRow, w.nextRowToWrite-1))
+		w.subtotals.previousRanges[i] = append(w.subtotals.previousRanges[i], ref)
+	}
+	return nil
+}
+
+// styleSubtotals styles the subtotal rows like the totals row, after the column styles so they are not replaced
+func (w *TypeWriter[T]) styleSubtotals() error {
+	if w.subtotals == nil || len(w.subtotals.rows) == 0 {
+		return nil
+	}
+	labelStyle := 0
+	if w.options.TotalsStyle != nil {
+		var err error
+		if labelStyle, err = w.file.NewStyle(w.options.TotalsStyle); err != nil {
+			return fmt.Errorf("error creating totals style: %w", err)
+		}
+	}
+	for k, i := range w.visibleColumns {
+		aggregate := w.subtotals.aggregates[i]
+		if aggregate == "" && (i != w.subtotals.column || labelStyle == 0) {
+			continue
+		}
+		column, err := excelize.ColumnNumberToName(w.columnNumber(k))
+		if err != nil {
+			return err
+		}
+		for _, row := range w.subtotals.rows {
+			if aggregate != "" {
+				err = w.styleTotal(w.typeInfo.nameToField[w.headers[i]], aggregate, column, row)
+			} else {
+				cell := fmt.Sprintf("%s%d", column, row)
+				err = w.file.SetCellStyle(cell, cell, labelStyle)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package gexelizer
+
+import (
+	"bytes"
+	"github.com/xuri/excelize/v2"
+	"reflect"
+	"testing"
+)
+
+func TestSubtotals_WriteAndRead(t *testing.T) {
+	type sale struct {
+		Region   string  `gex:"column:region"`
+		Discount string  `gex:"column:discount,omitempty"`
+		Customer string  `gex:"column:customer"`
+		Amount   float64 `gex:"column:amount,sum"`
+		Units    int     `gex:"column:units,max"`
+	}
+	data := []sale{
+		{Region: "East", Customer: "Acme", Amount: 100, Units: 1},
+		{Region: "East", Customer: "Globex", Amount: 50, Units: 3},
+		{Region: "West", Customer: "Initech", Amount: 70, Units: 2},
+	}
+	buffer, err := WriteExcelToBuffer(data, WithSubtotals("Region", "amount", "units"), WithSubtotalOutline(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := file.GetRows("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	//The empty discount column is removed, the subtotal formulas follow their columns and hold no value until calculated
+	expectedRows := [][]string{
+		{"Region", "Customer", "Amount", "Units"},
+		{"East", "Acme", "100", "1"},
+		{"East", "Globex", "50", "3"},
+		{"East Total", "", "", ""},
+		{"West", "Initech", "70", "2"},
+		{"West Total", "", "", ""},
+		{"Total", "", "", ""},
+	}
+	if !reflect.DeepEqual(rows, expectedRows) {
+		t.Fatalf("expected %v, got %v", expectedRows, rows)
+	}
+	expectedValues := map[string]string{"C4": "150", "D4": "3", "C6": "70", "D6": "2"}
+	for cell, expected := range expectedValues {
+		if value, err := file.CalcCellValue("Sheet1", cell); err != nil || value != expected {
+			formula, _ := file.GetCellFormula("Sheet1", cell)
+			t.Fatalf("expected %s to calculate to %s, got %q (%v) from %s", cell, expected, value, err, formula)
+		}
+	}
+	//Excel leaves the subtotals out of the grand total, the calculation engine of excelize does not
+	if formula, _ := file.GetCellFormula("Sheet1", "C7"); formula != "SUBTOTAL(109,C2:C6)" {
+		t.Fatalf("expected the grand total over every row, got %q", formula)
+	}
+	for i, expected := range []uint8{0, 1, 1, 0, 1, 0, 0} {
+		if level, _ := file.GetRowOutlineLevel("Sheet1", i+1); level != expected {
+			t.Fatalf("expected row %d at outline level %d, got %d", i+1, expected, level)
+		}
+	}
+
+	read, err := ReadExcel[sale](bytes.NewReader(buffer.Bytes()), WithTrimEmptyRows(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, data) {
+		t.Fatalf("expected the subtotal rows to be skipped, got %+v", read)
+	}
+
+	invalid := [][]Option{
+		{WithSubtotals("region")},
+		{WithSubtotals("unknown", "amount")},
+		{WithSubtotals("region", "customer")},
+		{WithSubtotals("", "amount")},
+	}
+	for _, opts := range invalid {
+		if _, err := NewTypeWriter[sale](opts...); err == nil {
+			t.Fatalf("expected %+v to be rejected", opts)
+		}
+	}
+}
+
+func TestSubtotals_ReadWithoutAggregateTags(t *testing.T) {
+	type sale struct {
+		Region string  `gex:"column:region"`
+		Amount float64 `gex:"column:amount"`
+	}
+	data := []sale{
+		{Region: "East", Amount: 100},
+		{Region: "East", Amount: 50},
+		{Region: "West", Amount: 70},
+	}
+	buffer, err := WriteExcelToBuffer(data, WithSubtotals("region", "amount"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	//Without aggregate tags subtotal rows are only looked for with a subtotal option
+	read, err := ReadExcel[sale](bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) == len(data) {
+		t.Fatalf("expected the subtotal rows to be read without a subtotal option, got %+v", read)
+	}
+	for _, opts := range [][]Option{{WithSubtotals("region", "amount")}, {WithSubtotals("region")}} {
+		read, err = ReadExcel[sale](bytes.NewReader(buffer.Bytes()), opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(read, data) {
+			t.Fatalf("expected the subtotal rows to be skipped, got %+v", read)
+		}
+	}
+}
+
+func TestSubtotals_ParentValuesCountOnce(t *testing.T) {
+	type item struct {
+		SKU string `gex:"column:sku"`
+	}
+	type order struct {
+		Region string `gex:"column:region,primary"`
+		Items  []item `gex:"column:items"`
+		Total  int    `gex:"column:total"`
+	}
+	data := []order{{Region: "East", Items: []item{{"a"}, {"b"}}, Total: 5}}
+	if _, err := WriteExcelToBuffer(data, WithSubtotals("region", "total")); err == nil {
+		t.Fatal("expected a subtotal of parent values repeated on every slice row to be rejected")
+	}
+	buffer, err := WriteExcelToBuffer(data, WithSubtotals("region", "total"), WithParentCells(ParentCellsBlank))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := file.CalcCellValue("Sheet1", "C4"); err != nil || value != "5" {
+		formula, _ := file.GetCellFormula("Sheet1", "C4")
+		t.Fatalf("expected the subtotal of the group to be 5, got %q (%v) from %s", value, err, formula)
+	}
+}
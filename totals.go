@@ -0,0 +1,179 @@
+package gexelizer
+
+import (
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"strings"
+)
+
+// subtotalFunctions are the SUBTOTAL function numbers of the aggregates, ignoring hidden rows and other subtotals
+var subtotalFunctions = map[string]int{
+	aggregateAvg:   101,
+	aggregateCount: 103,
+	aggregateMax:   104,
+	aggregateMin:   105,
+	aggregateSum:   109,
+}
+
+// subtotalFormula returns the formula aggregating the ranges, see columnRange
+func subtotalFormula(aggregate string, ranges ...string) Formula {
+	return Formula(fmt.Sprintf("SUBTOTAL(%d,%s)", subtotalFunctions[aggregate], strings.Join(ranges, ",")))
+}
+
+// columnRange returns the range of the rows from firstRow to lastRow of column, e.g. "C2:C10"
+func columnRange(column string, firstRow, lastRow uint) string {
+	return fmt.Sprintf("%s%d:%s%d", column, firstRow, column, lastRow)
+}
+
+// isSubtotalFormula reports whether formula is written by an aggregate, which marks totals rows
+func isSubtotalFormula(formula string) bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimPrefix(formula, "=")), "SUBTOTAL(")
+}
+
+// analyzeTotals rejects aggregates of parent columns whose values repeat on every slice row, see repeatsParentValues
+func (w *TypeWriter[T]) analyzeTotals() error {
+	if !w.repeatsParentValues() {
+		return nil
+	}
+	for i, header := range w.headers {
+		if fi := w.typeInfo.nameToField[header]; w.parentColumns[i] && fi.aggregate != "" {
+			return fmt.Errorf("aggregate %s of column %s would count its value once per slice row, "+
+				"write the slice to a child sheet or with blank or merged parent cells", fi.aggregate, fi.name)
+		}
+	}
+	return nil
+}
+
+// repeatsParentValues reports whether the values of parent columns are written on every row of a slice,
+// so aggregating them counts a value more than once
+func (w *TypeWriter[T]) repeatsParentValues() bool {
+	return w.parentColumns != nil && w.options.ChildSheet == "" && w.options.ParentCells == ParentCellsRepeat
+}
+
+// addTotals writes the totals row below the written rows if columns have an aggregate tag,
+// its label in the first column and SUBTOTAL formulas over the data rows in the aggregate columns
+func (w *TypeWriter[T]) addTotals() error {
+	if w.columnContainsValues == nil || w.nextRowToWrite <= w.options.DataStartRow {
+		return nil
+	}
+	aggregated := false
+	for _, i := range w.visibleColumns {
+		if w.typeInfo.nameToField[w.headers[i]].aggregate != "" {
+			aggregated = true
+		}
+	}
+	if !aggregated {
+		return nil
+	}
+	row := w.nextRowToWrite
+	if w.options.TotalsStyle != nil {
+		style, err := w.file.NewStyle(w.options.TotalsStyle)
+		if err != nil {
+			return fmt.Errorf("error creating totals style: %w", err)
+		}
+		start, _ := excelize.CoordinatesToCellName(w.options.startColumn(), int(row))
+		end, _ := excelize.CoordinatesToCellName(w.options.startColumn()+w.columnCount()-1, int(row))
+		if err := w.file.SetCellStyle(start, end, style); err != nil {
+			return err
+		}
+	}
+	for k, i := range w.visibleColumns {
+		fi := w.typeInfo.nameToField[w.headers[i]]
+		column, err := excelize.ColumnNumberToName(w.columnNumber(k))
+		if err != nil {
+			return err
+		}
+		if fi.aggregate == "" {
+			if k == 0 && w.options.TotalsLabel != "" {
+				if err := w.file.SetRow(uint(w.columnNumber(k)), row, []any{w.options.TotalsLabel}); err != nil {
+					return err
+				}
+				w.trackLength(i, w.options.TotalsLabel)
+			}
+			continue
+		}
+		formula := subtotalFormula(fi.aggregate, columnRange(column, w.options.DataStartRow, row-1))
+		if err := w.file.SetRow(uint(w.columnNumber(k)), row, []any{formula}); err != nil {
+			return err
+		}
+		if err := w.styleTotal(fi, fi.aggregate, column, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// styleTotal styles the aggregate cell of a column with the totals style and the number format of the column,
+// counts are plain numbers whatever the column holds
+func (w *TypeWriter[T]) styleTotal(fi fieldInfo, aggregate, column string, row uint) error {
+	style := excelize.Style{}
+	styled := w.options.TotalsStyle != nil
+	if styled {
+		style = *w.options.TotalsStyle
+	}
+	if columnStyle, ok := columnStyle(fi, w.options); ok && aggregate != aggregateCount && style.NumFmt == 0 && style.CustomNumFmt == nil {
+		style.NumFmt = columnStyle.NumFmt
+		style.CustomNumFmt = columnStyle.CustomNumFmt
+		styled = true
+	}
+	if !styled {
+		return nil
+	}
+	styleID, err := w.file.NewStyle(&style)
+	if err != nil {
+		return fmt.Errorf("error creating totals style of column %s: %w", fi.name, err)
+	}
+	cell := fmt.Sprintf("%s%d", column, row)
+	return w.file.SetCellStyle(cell, cell, styleID)
+}
+
+// isTotalsRow reports whether row is a totals or subtotal row, an aggregated column of which holds a SUBTOTAL formula
+func (t *TypeReader[T]) isTotalsRow(row []string) bool {
+	if len(t.totalsColumns) == 0 || !t.mayBeTotalsRow(row) {
+		return false
+	}
+	for _, col := range t.totalsColumns {
+		cell, exists := t.currentCell(col)
+		if !exists {
+			continue
+		}
+		if formula, err := t.file.GetCellFormula(t.sheet, cell); err == nil && isSubtotalFormula(formula) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTotalsColumn reports whether the SUBTOTAL formulas of column fi mark totals and subtotal rows to skip.
+// These are the columns with an aggregate tag and the subtotal columns of the options, or every number and date
+// column if only the subtotal group is set
+func (t *TypeReader[T]) isTotalsColumn(fi fieldInfo) bool {
+	if fi.aggregate != "" {
+		return true
+	}
+	for _, col := range t.options.SubtotalColumns {
+		if subtotal, exists := t.typeInfo.nameToField[strings.ToLower(strings.TrimSpace(col))]; exists && strings.EqualFold(subtotal.name, fi.name) {
+			return true
+		}
+	}
+	return t.options.SubtotalGroup != "" && len(t.options.SubtotalColumns) == 0 &&
+		fi.readRaw && !fi.formula && fi.valueType != "true/false"
+}
+
+// mayBeTotalsRow reports whether row holds the totals label or no aggregated values, as written formulas have none.
+// Formulas are only looked up for these rows, looking them up cell by cell is too slow for every row
+func (t *TypeReader[T]) mayBeTotalsRow(row []string) bool {
+	if label := strings.ToLower(t.options.TotalsLabel); label != "" {
+		for _, value := range row {
+			if strings.HasSuffix(strings.ToLower(strings.TrimSpace(value)), label) {
+				return true
+			}
+		}
+	}
+	for _, col := range t.totalsColumns {
+		if index, exists := t.headersToIndex[col]; exists && index < len(row) && strings.TrimSpace(row[index]) != "" {
+			return false
+		}
+	}
+	return true
+}
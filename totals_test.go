@@ -0,0 +1,152 @@
+package gexelizer
+
+import (
+	"bytes"
+	"github.com/xuri/excelize/v2"
+	"reflect"
+	"testing"
+)
+
+func TestTotals_WriteAndRead(t *testing.T) {
+	type expense struct {
+		Category string  `gex:"column:category"`
+		Amount   float64 `gex:"column:amount,sum,numfmt:4"`
+		Items    int     `gex:"column:items,avg"`
+		Spent    Date    `gex:"column:spent,max"`
+		Note     string  `gex:"column:note,count"`
+	}
+	data := []expense{
+		{Category: "travel", Amount: 1200.5, Items: 2, Spent: "2024-03-01", Note: "flights"},
+		{Category: "food", Amount: 300.25, Items: 4, Spent: "2024-03-05"},
+		{Category: "office", Amount: 99.25, Items: 6, Spent: "2024-02-20", Note: "paper"},
+	}
+	buffer, err := WriteExcelToBuffer(data, WithTotalsLabel("Grand total"), WithTotalsStyle(&excelize.Style{Font: &excelize.Font{Bold: true}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if label, _ := file.GetCellValue("Sheet1", "A5"); label != "Grand total" {
+		t.Fatalf("expected the totals label below the data, got %q", label)
+	}
+	expectedFormulas := map[string]string{
+		"B5": "SUBTOTAL(109,B2:B4)",
+		"C5": "SUBTOTAL(101,C2:C4)",
+		"D5": "SUBTOTAL(104,D2:D4)",
+		"E5": "SUBTOTAL(103,E2:E4)",
+	}
+	for cell, expected := range expectedFormulas {
+		if formula, _ := file.GetCellFormula("Sheet1", cell); formula != expected {
+			t.Fatalf("expected %s in %s, got %q", expected, cell, formula)
+		}
+	}
+	for cell, expected := range map[string]string{"B5": "1,600.00", "C5": "4", "D5": "2024-03-05", "E5": "2"} {
+		if value, err := file.CalcCellValue("Sheet1", cell); err != nil || value != expected {
+			t.Fatalf("expected %s to calculate to %s, got %q (%v)", cell, expected, value, err)
+		}
+	}
+	styleID, _ := file.GetCellStyle("Sheet1", "B5")
+	if style, _ := file.GetStyle(styleID); style.Font == nil || !style.Font.Bold || style.NumFmt != 4 {
+		t.Fatalf("expected a bold total keeping the number format, got %+v", style)
+	}
+
+	read, err := ReadExcel[expense](bytes.NewReader(buffer.Bytes()), WithTrimEmptyRows(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, data) {
+		t.Fatalf("expected the totals row to be skipped, got %+v", read)
+	}
+
+	type invalid struct {
+		Name string `gex:"column:name,sum"`
+	}
+	if _, err := NewTypeWriter[invalid](); err == nil {
+		t.Fatal("expected a sum of text to be rejected")
+	}
+	type several struct {
+		Amount float64 `gex:"column:amount,sum,aggregate:avg"`
+	}
+	if _, err := NewTypeWriter[several](); err == nil {
+		t.Fatal("expected several aggregates of a column to be rejected")
+	}
+	type unknown struct {
+		Amount float64 `gex:"column:amount,aggregate:median"`
+	}
+	if _, err := NewTypeWriter[unknown](); err == nil {
+		t.Fatal("expected an unknown aggregate to be rejected")
+	}
+	type aliased struct {
+		Amount float64 `gex:"column:amount,aggregate:avg"`
+	}
+	aliasWriter, err := NewTypeWriter[aliased]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aggregate := aliasWriter.typeInfo.nameToField["amount"].aggregate; aggregate != aggregateAvg {
+		t.Fatalf("expected aggregate:avg to aggregate like avg, got %q", aggregate)
+	}
+	type validated struct {
+		Amount float64 `gex:"column:amount,min:0,max:100"`
+	}
+	w, err := NewTypeWriter[validated]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aggregate := w.typeInfo.nameToField["amount"].aggregate; aggregate != "" {
+		t.Fatalf("expected min: and max: to validate without aggregating, got aggregate %s", aggregate)
+	}
+}
+
+func TestTotals_ParentValuesCountOnce(t *testing.T) {
+	type item struct {
+		SKU string `gex:"column:sku"`
+	}
+	type order struct {
+		ID    int    `gex:"column:id,primary"`
+		Items []item `gex:"column:items"`
+		Total int    `gex:"column:total,sum"`
+	}
+	data := []order{
+		{ID: 1, Items: []item{{"a"}, {"b"}}, Total: 10},
+		{ID: 2, Items: []item{{"c"}, {"d"}}, Total: 20},
+	}
+	if _, err := WriteExcelToBuffer(data); err == nil {
+		t.Fatal("expected a total of parent values repeated on every slice row to be rejected")
+	}
+	if _, err := WriteExcelToBuffer(data, WithOutlineSlices(true)); err == nil {
+		t.Fatal("expected a total of parent values repeated on summary and slice rows to be rejected")
+	}
+	for name, opts := range map[string][]Option{
+		"blank":   {WithParentCells(ParentCellsBlank)},
+		"merge":   {WithParentCells(ParentCellsMerge)},
+		"outline": {WithParentCells(ParentCellsBlank), WithOutlineSlices(true)},
+		"child":   {WithChildSheet("Items")},
+	} {
+		buffer, err := WriteExcelToBuffer(data, opts...)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		file, err := excelize.OpenReader(bytes.NewReader(buffer.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		//Excel reads merged cells from their first cell, the calculation engine of excelize from every cell
+		merged, _ := file.GetMergeCells("Sheet1")
+		for _, cells := range merged {
+			_ = file.UnmergeCell("Sheet1", cells.GetStartAxis(), cells.GetEndAxis())
+		}
+		rows, err := file.GetRows("Sheet1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		//The totals row is the last one, its total in the last column
+		cell, _ := excelize.CoordinatesToCellName(len(rows[0]), len(rows))
+		if value, err := file.CalcCellValue("Sheet1", cell); err != nil || value != "30" {
+			formula, _ := file.GetCellFormula("Sheet1", cell)
+			t.Fatalf("%s: expected the total of both orders to be 30, got %q (%v) from %s", name, value, err, formula)
+		}
+	}
+}
@@ -2,10 +2,13 @@ package gexelizer
 
 import (
 	"fmt"
+	"github.com/xuri/excelize/v2"
 	"reflect"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 type fieldInfo struct {
@@ -19,6 +22,41 @@ type fieldInfo struct {
 	required     bool
 	omitEmpty    bool
 	defaultValue string
+	// formula fields are read from the formula text of the cell, values starting with "=" are written as formulas
+	formula bool
+	// image fields hold an Image, read from the picture anchored at the cell
+	image     bool
+	hyperlink bool
+	// date fields hold a Date and dateTime fields a time.Time, written as native dates
+	date     bool
+	dateTime bool
+	// readRaw fields are read from the cell value without its number format, so formatted numbers and dates still parse
+	readRaw bool
+	// numFmt, width, align and wrap style the written column
+	numFmt string
+	width  float64
+	align  string
+	wrap   bool
+	// validation restricts the values of the written column, nil if it has none
+	validation *columnValidation
+	// description and valueType describe the column in templates
+	description string
+	valueType   string
+	// label is the written header when it differs from the column name columns are matched by
+	label string
+	// aggregate is the sum, avg, count, min or max of the column in the written totals row, empty if it has none
+	aggregate string
+}
+
+// columnValidation is a data validation of a written column, from oneof, min, max and errormsg tags or a bool field
+type columnValidation struct {
+	oneOf []string
+	// minimum and maximum are formulas of the bounds, empty when unbounded
+	minimum, maximum string
+	rangeType        excelize.DataValidationType
+	// bounds describes the range in the words of the tags, e.g. "between 1 and 10"
+	bounds   string
+	errorMsg string
 }
 
 func (i fieldInfo) isChildOf(b fieldInfo) bool {
@@ -45,6 +83,7 @@ func (i fieldInfo) equal(b fieldInfo) bool {
 	return i.name == b.name && i.order == b.order && i.nextPrefix == b.nextPrefix && i.isPrimaryKey == b.isPrimaryKey && i.kind == b.kind
 }
 
+// typeInfo is the result of analyzing a struct type
 type typeInfo struct {
 	t              reflect.Type
 	primaryKeyName string
@@ -57,7 +96,23 @@ func (info typeInfo) containsSlice() bool {
 	return info.sliceFieldInfo != nil
 }
 
-func (info typeInfo) sortColumns() {
+// clone copies the columns and fields of info, so the copy can be modified without affecting info.
+// The slices of the fields are shared, as they are never modified after analysis
+func (info typeInfo) clone() typeInfo {
+	info.orderedColumns = append([]string(nil), info.orderedColumns...)
+	nameToField := make(map[string]fieldInfo, len(info.nameToField))
+	for name, fi := range info.nameToField {
+		nameToField[name] = fi
+	}
+	info.nameToField = nameToField
+	if info.sliceFieldInfo != nil {
+		sliceFieldInfo := *info.sliceFieldInfo
+		info.sliceFieldInfo = &sliceFieldInfo
+	}
+	return info
+}
+
+func (info *typeInfo) sortColumns() {
 	sort.SliceStable(info.orderedColumns, func(a, b int) bool {
 		nameA := info.orderedColumns[a]
 		nameB := info.orderedColumns[b]
@@ -93,6 +148,34 @@ func (info typeInfo) sortColumns() {
 	}
 }
 
+// typeInfoKey identifies a cached analysis, options affecting the analysis have to be part of the key
+type typeInfoKey struct {
+	t reflect.Type
+}
+
+type typeInfoEntry struct {
+	info typeInfo
+	err  error
+}
+
+// typeInfoCache maps typeInfoKey to typeInfoEntry
+var typeInfoCache sync.Map
+
+// cachedTypeInfo returns the analysis of t, running analyzeType only the first time a type is seen.
+// Every call returns its own copy, the cached analysis is never handed out
+func cachedTypeInfo(t reflect.Type) (typeInfo, error) {
+	key := typeInfoKey{t: t}
+	entry, ok := typeInfoCache.Load(key)
+	if !ok {
+		info, err := analyzeType(t)
+		entry, _ = typeInfoCache.LoadOrStore(key, typeInfoEntry{info: info, err: err})
+	}
+	if err := entry.(typeInfoEntry).err; err != nil {
+		return typeInfo{}, err
+	}
+	return entry.(typeInfoEntry).info.clone(), nil
+}
+
 func analyzeType(t reflect.Type) (typeInfo, error) {
 	isStruct := t.Kind() == reflect.Struct
 	isStructPtr := t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct
@@ -245,6 +328,26 @@ func analyzeField(field reflect.StructField, currentNode toTraverse, i int) (fie
 			return fieldInfo{}, fmt.Errorf("unsupported slice type: %s", field.Type.Elem().Kind())
 		}
 	}
+	if tagOpts.formula && field.Type.Kind() != reflect.String {
+		return fieldInfo{}, fmt.Errorf("formula field %s must be a string", field.Name)
+	}
+	var width float64
+	if tagOpts.width != "" {
+		if width, err = strconv.ParseFloat(tagOpts.width, 64); err != nil || width <= 0 || width > excelize.MaxColumnWidth {
+			return fieldInfo{}, fmt.Errorf("invalid width %s of field %s: must be a number between 0 and %d", tagOpts.width, field.Name, excelize.MaxColumnWidth)
+		}
+	}
+	if tagOpts.align != "" && !isHorizontalAlignment(tagOpts.align) {
+		return fieldInfo{}, fmt.Errorf("invalid alignment %s of field %s", tagOpts.align, field.Name)
+	}
+	validation, err := parseValidation(field, tagOpts)
+	if err != nil {
+		return fieldInfo{}, err
+	}
+	aggregate, err := parseAggregate(field, tagOpts.aggregates)
+	if err != nil {
+		return fieldInfo{}, err
+	}
 	// Get field prefix
 	prefix := getNextFieldPrefix(field, tagOpts.column, currentNode.columnPrefix, typeKind)
 	for i, alias := range tagOpts.aliases {
@@ -261,6 +364,21 @@ func analyzeField(field reflect.StructField, currentNode toTraverse, i int) (fie
 		nextPrefix:   prefix, //For nested structs
 		required:     tagOpts.required,
 		defaultValue: tagOpts.defaultValue,
+		formula:      tagOpts.formula || field.Type == reflect.TypeOf(Formula("")),
+		image:        field.Type == imageType || field.Type == reflect.PointerTo(imageType),
+		hyperlink:    field.Type == hyperlinkType || field.Type == reflect.PointerTo(hyperlinkType),
+		date:         isType(field.Type, dateType),
+		dateTime:     isType(field.Type, timeType),
+		readRaw:      isType(field.Type, dateType) || isType(field.Type, timeType) || isNumericOrBool(field.Type),
+		numFmt:       tagOpts.numFmt,
+		width:        width,
+		align:        tagOpts.align,
+		wrap:         tagOpts.wrap,
+		validation:   validation,
+		description:  tagOpts.description,
+		valueType:    valueTypeName(field.Type),
+		label:        tagOpts.label,
+		aggregate:    aggregate,
 	}, nil
 }
 
@@ -271,12 +389,24 @@ type tagOptions struct {
 	primaryKey   bool
 	required     bool
 	omitEmpty    bool
+	formula      bool
 	aliases      []string
+	numFmt       string
+	width        string
+	align        string
+	wrap         bool
+	oneOf        []string
+	minimum      string
+	maximum      string
+	errorMsg     string
+	description  string
+	label        string
+	aggregates   []string
 }
 
 func parseTagOptions(field reflect.StructField, i int) tagOptions {
 	tag := field.Tag.Get(mainTag)
-	segments := strings.Split(tag, mainSeparator)
+	segments := splitTag(tag)
 	options := tagOptions{}
 	for _, o := range segments {
 		//Column aliases
@@ -306,6 +436,61 @@ func parseTagOptions(field reflect.StructField, i int) tagOptions {
 			options.order = order
 			continue
 		}
+		//Column style
+		if strings.HasPrefix(o, numFmtTag) {
+			options.numFmt = strings.TrimPrefix(o, numFmtTag)
+			continue
+		}
+		if strings.HasPrefix(o, widthTag) {
+			options.width = strings.TrimSpace(strings.TrimPrefix(o, widthTag))
+			continue
+		}
+		if strings.HasPrefix(o, alignTag) {
+			options.align = strings.TrimSpace(strings.TrimPrefix(o, alignTag))
+			continue
+		}
+		//Data validation
+		if strings.HasPrefix(o, oneOfTag) {
+			for _, value := range strings.Split(strings.TrimPrefix(o, oneOfTag), listSeparator) {
+				if value = strings.TrimSpace(value); value != "" {
+					options.oneOf = append(options.oneOf, value)
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(o, minTag) {
+			options.minimum = strings.TrimSpace(strings.TrimPrefix(o, minTag))
+			continue
+		}
+		if strings.HasPrefix(o, maxTag) {
+			options.maximum = strings.TrimSpace(strings.TrimPrefix(o, maxTag))
+			continue
+		}
+		if strings.HasPrefix(o, labelTag) {
+			options.label = strings.TrimSpace(strings.TrimPrefix(o, labelTag))
+			continue
+		}
+		if strings.HasPrefix(o, descTag) {
+			options.description = strings.TrimPrefix(o, descTag)
+			continue
+		}
+		if strings.HasPrefix(o, errorMsgTag) {
+			options.errorMsg = strings.TrimPrefix(o, errorMsgTag)
+			continue
+		}
+		if strings.TrimSpace(o) == wrapTag {
+			options.wrap = true
+			continue
+		}
+		//Totals row
+		if aggregate := strings.TrimSpace(o); isAggregate(aggregate) {
+			options.aggregates = append(options.aggregates, aggregate)
+			continue
+		}
+		if strings.HasPrefix(o, aggregateTag) {
+			options.aggregates = append(options.aggregates, strings.TrimSpace(strings.TrimPrefix(o, aggregateTag)))
+			continue
+		}
 		//Default
 		if strings.HasPrefix(o, defaultTag) {
 			options.defaultValue = strings.TrimPrefix(o, defaultTag)
@@ -321,6 +506,11 @@ func parseTagOptions(field reflect.StructField, i int) tagOptions {
 			options.omitEmpty = true
 			continue
 		}
+		//Formula
+		if strings.TrimSpace(o) == formulaTag {
+			options.formula = true
+			continue
+		}
 		//Primary Key
 		if strings.TrimSpace(o) == primaryKeyTag {
 			options.primaryKey = true
@@ -333,6 +523,198 @@ func parseTagOptions(field reflect.StructField, i int) tagOptions {
 	return options
 }
 
+// splitTag splits a tag into its options. Values quoted with single quotes may contain the separator,
+// e.g. desc:'Name, as on the passport', and are unquoted
+func splitTag(tag string) []string {
+	var segments []string
+	start, quoteStart := 0, -1
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\'' && quoteStart < 0 && i > start && tag[i-1] == ':':
+			quoteStart = i
+		case tag[i] == '\'' && quoteStart >= 0 && (i+1 == len(tag) || strings.HasPrefix(tag[i+1:], mainSeparator)):
+			segments = append(segments, tag[start:quoteStart]+tag[quoteStart+1:i])
+			quoteStart = -1
+			i += len(mainSeparator)
+			start = i + 1
+		case quoteStart < 0 && strings.HasPrefix(tag[i:], mainSeparator):
+			segments = append(segments, tag[start:i])
+			start = i + len(mainSeparator)
+		}
+	}
+	if start <= len(tag) {
+		segments = append(segments, tag[start:])
+	}
+	return segments
+}
+
+// parseValidation returns the data validation of the field from its tags, a bool field gets a TRUE/FALSE dropdown
+func parseValidation(field reflect.StructField, tagOpts tagOptions) (*columnValidation, error) {
+	validation := &columnValidation{errorMsg: tagOpts.errorMsg}
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case len(tagOpts.oneOf) > 0:
+		validation.oneOf = tagOpts.oneOf
+	case tagOpts.minimum != "" || tagOpts.maximum != "":
+		var parse func(s string) (string, error)
+		switch {
+		case t == dateType || t == timeType:
+			validation.rangeType = excelize.DataValidationTypeDate
+			parse = func(s string) (string, error) {
+				date, err := ParseTime(s)
+				if err != nil {
+					return "", err
+				}
+				return strconv.FormatFloat(excelSerial(date), 'f', -1, 64), nil
+			}
+		case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+			validation.rangeType = excelize.DataValidationTypeDecimal
+			parse = func(s string) (string, error) {
+				_, err := strconv.ParseFloat(s, 64)
+				return s, err
+			}
+		case isNumericOrBool(t) && t.Kind() != reflect.Bool:
+			validation.rangeType = excelize.DataValidationTypeWhole
+			parse = func(s string) (string, error) {
+				_, err := strconv.ParseInt(s, 10, 64)
+				return s, err
+			}
+		default:
+			return nil, fmt.Errorf("min and max of field %s require a number or date field", field.Name)
+		}
+		var err error
+		if tagOpts.minimum != "" {
+			if validation.minimum, err = parse(tagOpts.minimum); err != nil {
+				return nil, fmt.Errorf("invalid min %s of field %s: %w", tagOpts.minimum, field.Name, err)
+			}
+		}
+		if tagOpts.maximum != "" {
+			if validation.maximum, err = parse(tagOpts.maximum); err != nil {
+				return nil, fmt.Errorf("invalid max %s of field %s: %w", tagOpts.maximum, field.Name, err)
+			}
+		}
+		switch {
+		case tagOpts.maximum == "":
+			validation.bounds = "of at least " + tagOpts.minimum
+		case tagOpts.minimum == "":
+			validation.bounds = "of at most " + tagOpts.maximum
+		default:
+			validation.bounds = "between " + tagOpts.minimum + " and " + tagOpts.maximum
+		}
+	case t.Kind() == reflect.Bool:
+		validation.oneOf = []string{"TRUE", "FALSE"}
+	default:
+		return nil, nil
+	}
+	return validation, nil
+}
+
+// valueTypeName describes the values of t to people filling in a template
+func valueTypeName(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == dateType:
+		return "date"
+	case t == timeType:
+		return "date and time"
+	case t == hyperlinkType:
+		return "link"
+	case t == imageType:
+		return "image"
+	case t == reflect.TypeOf(Formula("")):
+		return "formula"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "true/false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "whole number"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return "text"
+}
+
+// excelSerial converts t into an Excel serial date number
+func excelSerial(t time.Time) float64 {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return wall.Sub(epoch).Hours() / 24
+}
+
+var (
+	dateType = reflect.TypeOf(Date(""))
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+// isType reports whether t is target or a pointer to it
+func isType(t reflect.Type, target reflect.Type) bool {
+	return t == target || (t.Kind() == reflect.Ptr && t.Elem() == target)
+}
+
+func isNumericOrBool(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isAggregate(tag string) bool {
+	switch tag {
+	case aggregateSum, aggregateAvg, aggregateCount, aggregateMin, aggregateMax:
+		return true
+	}
+	return false
+}
+
+// parseAggregate returns the aggregate of a field in the totals row, sum and avg need numbers and min and max numbers or dates
+func parseAggregate(field reflect.StructField, aggregates []string) (string, error) {
+	if len(aggregates) == 0 {
+		return "", nil
+	}
+	if len(aggregates) > 1 {
+		return "", fmt.Errorf("field %s can have a single aggregate, got %s", field.Name, strings.Join(aggregates, ", "))
+	}
+	aggregate := aggregates[0]
+	if !isAggregate(aggregate) {
+		return "", fmt.Errorf("unknown aggregate %s of field %s, expected sum, avg, count, min or max", aggregate, field.Name)
+	}
+	if !aggregateSupported(aggregate, valueTypeName(field.Type)) {
+		return "", fmt.Errorf("aggregate %s is not supported by field %s of type %s", aggregate, field.Name, field.Type)
+	}
+	return aggregate, nil
+}
+
+// aggregateSupported reports whether a column of valueType can be aggregated, see valueTypeName
+func aggregateSupported(aggregate, valueType string) bool {
+	switch valueType {
+	case "whole number", "number":
+		return isAggregate(aggregate)
+	case "date", "date and time":
+		return aggregate == aggregateMin || aggregate == aggregateMax || aggregate == aggregateCount
+	}
+	return aggregate == aggregateCount
+}
+
+func isHorizontalAlignment(align string) bool {
+	switch align {
+	case "left", "center", "right", "fill", "justify", "centerContinuous", "distributed":
+		return true
+	}
+	return false
+}
+
 func getNextFieldPrefix(field reflect.StructField, name, prevPrefix string, k kind) string {
 	prefix := ""
 	segments := strings.Split(field.Tag.Get(mainTag), mainSeparator)
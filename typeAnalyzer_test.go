@@ -3,6 +3,7 @@ package gexelizer
 import (
 	"fmt"
 	"reflect"
+	"sync"
 	"testing"
 )
 
@@ -467,6 +468,121 @@ func TestTypeAnalyzer_SliceStruct(t *testing.T) {
 	}
 }
 
+func TestTypeAnalyzer_SplitTag(t *testing.T) {
+	tests := map[string][]string{
+		"":                                 {""},
+		"column:name,required":             {"column:name", "required"},
+		"column:name,desc:'Name, in full'": {"column:name", "desc:Name, in full"},
+		"errormsg:'Pick one, or none',aggregate:sum": {"errormsg:Pick one, or none", "aggregate:sum"},
+		"desc:Don't guess,required":                  {"desc:Don't guess", "required"},
+		"numfmt:'#,##0.0',width:12":                  {"numfmt:#,##0.0", "width:12"},
+	}
+	for tag, expected := range tests {
+		if got := splitTag(tag); !reflect.DeepEqual(got, expected) {
+			t.Fatalf("expected %q to split into %q, got %q", tag, expected, got)
+		}
+	}
+}
+
+func TestTypeAnalyzer_Cached(t *testing.T) {
+	type cached struct {
+		ID   int    `gex:"column:id,primary"`
+		Name string `gex:"column:name"`
+	}
+	first, err := cachedTypeInfo(reflect.TypeOf(cached{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := cachedTypeInfo(reflect.TypeOf(cached{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := typeInfoCache.Load(typeInfoKey{t: reflect.TypeOf(cached{})}); !ok {
+		t.Fatal("expected the analysis to be cached")
+	}
+	if err := typeInfosEqual(first, second); err != nil {
+		t.Fatal(err)
+	}
+	first.orderedColumns[0] = "changed"
+	first.nameToField["id"] = fieldInfo{name: "changed"}
+	third, _ := cachedTypeInfo(reflect.TypeOf(cached{}))
+	if err := typeInfosEqual(second, third); err != nil {
+		t.Fatalf("expected changes to a copy to leave the cache intact: %v", err)
+	}
+	type invalid struct {
+		One string `gex:"column:one,primary"`
+		Two string `gex:"column:two,primary"`
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := cachedTypeInfo(reflect.TypeOf(invalid{})); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+}
+
+func TestTypeAnalyzer_CachedConcurrent(t *testing.T) {
+	type concurrent struct {
+		ID   int    `gex:"column:id,primary"`
+		Name string `gex:"column:name"`
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			buffer, err := WriteExcelToBuffer([]concurrent{{ID: i, Name: "John"}})
+			if err != nil {
+				errs <- err
+				return
+			}
+			rows, err := ReadExcel[concurrent](buffer)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if len(rows) != 1 || rows[0].ID != i {
+				errs <- fmt.Errorf("unexpected rows %+v", rows)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func TestTypeAnalyzer_CachedCopiesConcurrent(t *testing.T) {
+	type concurrent struct {
+		ID    int    `gex:"column:id,primary"`
+		Name  string `gex:"column:name"`
+		Lines []struct {
+			Item string `gex:"column:item"`
+		}
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			info, err := cachedTypeInfo(reflect.TypeOf(concurrent{}))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			//Copies are modified freely, run with -race to catch shared state
+			info.sortColumns()
+			fi := info.nameToField["name"]
+			fi.order = i
+			info.nameToField["name"] = fi
+			info.orderedColumns[len(info.orderedColumns)-1] = fmt.Sprint(i)
+			info.sliceFieldInfo.order = i
+		}(i)
+	}
+	wg.Wait()
+}
+
 // typeInfoEqual compares two typeInfo structs and returns nil if they are equal, error otherwise, with expected and got values
 func typeInfosEqual(a, b typeInfo) error {
 	if len(a.nameToField) != len(b.nameToField) {
@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func parseStringIntoType(s string, t reflect.Type) (any, error) {
@@ -12,38 +13,72 @@ func parseStringIntoType(s string, t reflect.Type) (any, error) {
 	case reflect.String:
 		return s, nil
 	case reflect.Uint:
-		return parseUint(s)
+		return ParseUint(s)
 	case reflect.Int:
-		return parseInt(s)
+		return ParseInt(s)
 	case reflect.Int64:
-		return parseInt64(s)
+		return ParseInt64(s)
 	case reflect.Float64:
-		return parseFloat(s)
+		return ParseFloat(s)
 	case reflect.Bool:
-		return parseBool(s)
-	//case reflect.Struct: //TODO add Time and Decimal support
+		return ParseBool(s)
+	case reflect.Struct:
+		//TODO add Decimal support
+		if t == reflect.TypeOf(time.Time{}) {
+			return ParseTime(s)
+		}
+		return nil, fmt.Errorf("unsupported type %s", t.Kind())
 	default:
 		return nil, fmt.Errorf("unsupported type %s", t.Kind())
 	}
 }
 
-func parseUint(s string) (uint, error) {
+// The Parse* functions below are used both by the reflective reader and by code generated with cmd/gexgen,
+// so both paths accept exactly the same cell values.
+
+// ParseUint parses a cell value into uint
+func ParseUint(s string) (uint, error) {
 	i, err := strconv.Atoi(s)
 	return uint(i), err
 }
 
-func parseInt(s string) (int, error) {
+// ParseInt parses a cell value into int
+func ParseInt(s string) (int, error) {
 	return strconv.Atoi(s)
 }
-func parseInt64(s string) (int64, error) {
+
+// ParseInt64 parses a cell value into int64
+func ParseInt64(s string) (int64, error) {
 	return strconv.ParseInt(s, 10, 64)
 }
 
-func parseFloat(s string) (float64, error) {
+// ParseFloat parses a cell value into float64
+func ParseFloat(s string) (float64, error) {
 	return strconv.ParseFloat(s, 64)
 }
 
-func parseBool(s string) (bool, error) {
+// timeLayouts are the layouts ParseTime accepts, date cells are read as RFC 3339
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseTime parses a cell value into time.Time, accepting RFC 3339 and ISO 8601 dates with or without time
+func ParseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("could not parse time %s - unknown format", s)
+}
+
+// ParseBool parses a cell value into bool, accepting true/false, t/f, 1/0, yes/no and y/n in any case
+func ParseBool(s string) (bool, error) {
 	trues := []string{"true", "t", "1", "yes", "y"}
 	falses := []string{"false", "f", "0", "no", "n"}
 	s = strings.ToLower(s)
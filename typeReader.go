@@ -1,10 +1,14 @@
 package gexelizer
 
 import (
+	"errors"
 	"fmt"
+	"github.com/xuri/excelize/v2"
 	"io"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type TypeReader[T any] struct {
@@ -12,92 +16,87 @@ type TypeReader[T any] struct {
 	typeInfo       typeInfo
 	headers        []string
 	nextRowToRead  uint
-	options        *Options
+	options        Options
 	headersToIndex map[string]int
 	rows           [][]string
+	// sheet the rows are read from
+	sheet string
+	// images anchored in the cells of Image columns, by cell name
+	images map[string]Image
+	// childOnly reads the primary key and slice elements of a child sheet, see joinChildSheet
+	childOnly bool
+	// series tracks the sheets read with SheetSeries, nil while reading the first sheet
+	series *sheetSeries
+	// totalsColumns are the read columns that can be aggregated, whose SUBTOTAL formulas mark totals and subtotal rows to skip
+	totalsColumns []string
+	// rowNumbers holds the 1-based sheet row number of every row left after trimming
+	rowNumbers []int
+
+	// set when T has a generated row reader covering every column
+	fieldPositions  []int
+	fieldColumns    []string
+	generatedValues []string
 
 	previousPrimaryKey string
+	// previousRow is the last data row read, used to fill the blank parent cells of continuation rows
+	previousRow []string
 }
 
-func ReadXLSExcel[T any](reader io.ReadSeeker, opts ...Options) ([]T, error) {
-	r := &TypeReader[T]{}
-	file, err := readXLSExcel(reader)
+// ReadXLSExcel reads the legacy .xls file from reader into a slice of T objects
+func ReadXLSExcel[T any](reader io.ReadSeeker, opts ...Option) ([]T, error) {
+	r, err := newTypeReader[T](func(password string) (ExcelFileReader, error) {
+		return readXLSExcel(reader, password)
+	}, opts)
 	if err != nil {
 		return nil, err
 	}
-	r.file = file
-	if len(opts) > 0 {
-		r.options = &opts[0]
-	} else {
-		r.options = DefaultOptions()
-	}
-	r.options.HeaderRow -= 1
-	r.options.DataStartRow -= 1
-	r.nextRowToRead = r.options.HeaderRow
-	if err := r.analyzeType(); err != nil {
-		return nil, err
-	}
 	return r.Read()
 }
 
-func ReadExcel[T any](reader io.Reader, opts ...Options) ([]T, error) {
-	r := &TypeReader[T]{}
-	file, err := readExcel(reader)
+// ReadExcel reads the .xlsx file from reader into a slice of T objects
+func ReadExcel[T any](reader io.Reader, opts ...Option) ([]T, error) {
+	r, err := NewTypeReader[T](reader, opts...)
 	if err != nil {
 		return nil, err
 	}
-	r.file = file
-	if len(opts) > 0 {
-		r.options = &opts[0]
-	} else {
-		r.options = DefaultOptions()
-	}
-	r.options.HeaderRow -= 1
-	r.options.DataStartRow -= 1
-	r.nextRowToRead = r.options.HeaderRow
-	if err := r.analyzeType(); err != nil {
-		return nil, err
-	}
 	return r.Read()
 }
-func ReadExcelFile[T any](filename string, opts ...Options) ([]T, error) {
-	r := &TypeReader[T]{}
-	file, err := readExcelFile(filename)
+
+// ReadExcelFile reads the .xlsx file at filename into a slice of T objects
+func ReadExcelFile[T any](filename string, opts ...Option) ([]T, error) {
+	r, err := newTypeReader[T](func(password string) (ExcelFileReader, error) {
+		return readExcelFile(filename, password)
+	}, opts)
 	if err != nil {
 		return nil, err
 	}
-	r.file = file
-	if len(opts) > 0 {
-		r.options = &opts[0]
-	} else {
-		r.options = DefaultOptions()
-	}
-	r.options.HeaderRow -= 1
-	r.options.DataStartRow -= 1
-	r.nextRowToRead = r.options.HeaderRow
-	if err := r.analyzeType(); err != nil {
-		return nil, err
-	}
 	return r.Read()
 }
 
 // NewTypeReader creates a new TypeReader[T] instance
-func NewTypeReader[T any](reader io.Reader, opts ...Options) (*TypeReader[T], error) {
+func NewTypeReader[T any](reader io.Reader, opts ...Option) (*TypeReader[T], error) {
 	if reader == nil {
 		return nil, fmt.Errorf("reader cannot be nil")
 	}
-	r := &TypeReader[T]{}
-	file, err := readExcel(reader)
+	return newTypeReader[T](func(password string) (ExcelFileReader, error) {
+		return readExcel(reader, password)
+	}, opts)
+}
+
+// newTypeReader validates the options before opening the file, so invalid options fail fast
+func newTypeReader[T any](open func(password string) (ExcelFileReader, error), opts []Option) (*TypeReader[T], error) {
+	options, err := newOptions(opts...)
 	if err != nil {
 		return nil, err
 	}
-	r.file = file
-	if len(opts) > 0 {
-		r.options = &opts[0]
-	} else {
-		r.options = DefaultOptions()
+	file, err := open(options.Password)
+	if err != nil {
+		return nil, err
+	}
+	r := &TypeReader[T]{
+		file:    file,
+		options: options,
 	}
-	r.nextRowToRead = r.options.HeaderRow
 	if err := r.analyzeType(); err != nil {
 		return nil, err
 	}
@@ -113,19 +112,45 @@ func (t *TypeReader[T]) Read() (result []T, err error) {
 			err = fmt.Errorf("panic: %v", r)
 		}
 	}()
-	for i := 0; t.nextRowToRead < uint(len(t.rows)); i++ {
+	for {
+		if t.nextRowToRead >= uint(len(t.rows)) {
+			if !t.options.SheetSeries {
+				break
+			}
+			next, err := t.nextSheet()
+			if err != nil {
+				return nil, err
+			}
+			if !next {
+				break
+			}
+			continue
+		}
 		row := t.rows[t.nextRowToRead]
 		t.nextRowToRead++
+		if t.isTotalsRow(row) {
+			continue
+		}
+		if t.options.StopAt != nil && t.options.StopAt(row) {
+			break
+		}
+		if t.options.SkipRow != nil && t.options.SkipRow(row) {
+			continue
+		}
+		if t.typeInfo.containsSlice() && (t.options.ParentCells != ParentCellsRepeat || t.options.OutlineSlices) {
+			row = t.continueParentCells(row)
+			t.previousRow = row
+		}
 		var toRead T
 		pk, err := t.readSingle(row, &toRead)
 		if err != nil {
 			if rowErr, ok := err.(RowError); ok {
-				rowErr.RowNumber = int(t.nextRowToRead)
+				rowErr.RowNumber = t.rowNumbers[t.nextRowToRead-1]
 				return nil, rowErr
 			}
 			return nil, err
 		}
-		isFirstRow := i == 0
+		isFirstRow := len(result) == 0
 		// if the primary key is different than the previous one, append the object to the result
 		if isFirstRow || !t.typeInfo.containsSlice() || t.previousPrimaryKey != pk {
 			t.previousPrimaryKey = pk
@@ -139,11 +164,65 @@ func (t *TypeReader[T]) Read() (result []T, err error) {
 		currentSlice := reflect.ValueOf(toRead).FieldByIndex(sliceIndexInT)
 		previousSlice.Set(reflect.AppendSlice(previousSlice, currentSlice))
 	}
+	if t.options.ChildSheet != "" && t.typeInfo.containsSlice() {
+		if err := t.joinChildSheet(result); err != nil {
+			return nil, err
+		}
+	}
 	return result, nil
 }
 
+// expectsColumn reports whether the column of fi is on the read sheet, slice element columns are on the child sheet
+// of the options and child sheets hold the primary key and slice element columns only
+func (t *TypeReader[T]) expectsColumn(fi fieldInfo) bool {
+	if !t.typeInfo.containsSlice() {
+		return true
+	}
+	isChild := fi.isChildOf(*t.typeInfo.sliceFieldInfo)
+	if t.childOnly {
+		return isChild || fi.isPrimaryKey
+	}
+	return t.options.ChildSheet == "" || !isChild
+}
+
+// continueParentCells fills the blank parent cells of a row with a blank primary key from the previous row,
+// so rows written with merged or blank parent cells continue the previous value. It runs only when the options
+// read such sheets, otherwise a blank primary key is an error
+func (t *TypeReader[T]) continueParentCells(row []string) []string {
+	if t.previousRow == nil {
+		return row
+	}
+	sliceFI := *t.typeInfo.sliceFieldInfo
+	var parentIndexes []int
+	for _, col := range t.typeInfo.orderedColumns {
+		fi := t.typeInfo.nameToField[col]
+		index, exists := t.headersToIndex[col]
+		if !exists || fi.kind == kindSlice || fi.isChildOf(sliceFI) {
+			continue
+		}
+		if fi.isPrimaryKey && index < len(row) && strings.TrimSpace(row[index]) != "" {
+			return row
+		}
+		parentIndexes = append(parentIndexes, index)
+	}
+	continued := make([]string, len(row))
+	copy(continued, row)
+	if len(continued) < len(t.previousRow) {
+		continued = append(continued, make([]string, len(t.previousRow)-len(continued))...)
+	}
+	for _, index := range parentIndexes {
+		if strings.TrimSpace(continued[index]) == "" && index < len(t.previousRow) {
+			continued[index] = t.previousRow[index]
+		}
+	}
+	return continued
+}
+
 // ReadSingle reads a single row from the prepared excel file and returns the row parsed into T type object or an error
 func (t *TypeReader[T]) readSingle(row []string, toRead *T) (string, error) {
+	if t.fieldPositions != nil {
+		return "", t.readGenerated(row, toRead)
+	}
 	v := reflect.ValueOf(toRead).Elem()
 	if !t.typeInfo.containsSlice() {
 		return "", t.readSingleWithoutSlice(row, v)
@@ -157,7 +236,7 @@ func (t *TypeReader[T]) readSingle(row []string, toRead *T) (string, error) {
 	for i := 0; i < len(t.typeInfo.orderedColumns); i++ {
 		col := t.typeInfo.orderedColumns[i]
 		fi := t.typeInfo.nameToField[col]
-		if fi.kind == kindSlice {
+		if fi.kind == kindSlice || !t.expectsColumn(fi) {
 			continue
 		}
 		if fi.isChildOf(sliceFI) {
@@ -195,12 +274,26 @@ func (t *TypeReader[T]) readSingle(row []string, toRead *T) (string, error) {
 // if the field is optional and the value is empty or not present, it returns true
 // if the field is required and the value is empty or not present, it returns an error
 func (t *TypeReader[T]) setParsedValue(v reflect.Value, col string, info fieldInfo, row []string) (bool, error) {
+	rowVal, err := t.cellValue(col, info, row)
+	if err != nil {
+		return true, err
+	}
+	if rowVal == "" && !t.hasImage(col, info) {
+		return true, nil
+	}
+	return false, t.setValue(v, col, rowVal)
+}
+
+// cellValue returns the value of the column in the row, falling back to the default value of the field
+// if the field is optional and the value is empty or not present, it returns an empty string
+// if the field is required and the value is empty or not present, it returns an error
+func (t *TypeReader[T]) cellValue(col string, info fieldInfo, row []string) (string, error) {
 	headerIndex, columnExists := t.headersToIndex[col]
 	if !columnExists && info.defaultValue == "" {
 		if !info.required && !info.isPrimaryKey {
-			return true, nil
+			return "", nil
 		}
-		return true, fmt.Errorf("required column '%s' is not present", col)
+		return "", fmt.Errorf("required column '%s' is not present", col)
 	}
 	var rowVal string
 	if columnExists {
@@ -210,23 +303,82 @@ func (t *TypeReader[T]) setParsedValue(v reflect.Value, col string, info fieldIn
 		rowVal = info.defaultValue
 	}
 	// check if the field is optional and the value is empty
-	if rowVal == "" {
-		if !info.required && !info.isPrimaryKey {
-			return true, nil
-		}
+	if rowVal == "" && (info.required || info.isPrimaryKey) && !t.hasImage(col, info) {
 		//TODO here we have an issue, if struct is not present at all, required shouldn't be taken into consideration
-		return true, newNonIndexedRowError(fmt.Errorf("required column '%s' is empty", col))
+		return "", newNonIndexedRowError(fmt.Errorf("required column '%s' is empty", col))
 	}
-	if parsed, err := parseStringIntoType(rowVal, v.Type()); err != nil {
-		return false, newNonIndexedRowError(fmt.Errorf("error parsing cell value: %v, column: '%s'", err, col))
-	} else {
-		//TODO wrapper types are not supported
-		if v.Type() == reflect.TypeOf(Date("")) {
-			parsed = Date(rowVal)
+	return rowVal, nil
+}
+
+// setValue parses the non-empty rowVal into the field
+func (t *TypeReader[T]) setValue(v reflect.Value, col string, rowVal string) error {
+	if v.Type() == hyperlinkType || v.Type() == reflect.PointerTo(hyperlinkType) {
+		return t.setHyperlink(v, col, rowVal)
+	}
+	if v.Type() == imageType || v.Type() == reflect.PointerTo(imageType) {
+		return t.setImage(v, col)
+	}
+	if v.Kind() == reflect.Ptr {
+		//Pointer fields such as *time.Time are allocated only for cells with a value
+		elem := reflect.New(v.Type().Elem())
+		if err := t.setValue(elem.Elem(), col, rowVal); err != nil {
+			return err
 		}
-		v.Set(reflect.ValueOf(parsed))
+		v.Set(elem)
+		return nil
 	}
-	return false, nil
+	parsed, err := parseStringIntoType(rowVal, v.Type())
+	if err != nil {
+		return newNonIndexedRowError(fmt.Errorf("error parsing cell value: %v, column: '%s'", err, col))
+	}
+	//Named types such as Date or Formula are set through their underlying type
+	v.Set(reflect.ValueOf(parsed).Convert(v.Type()))
+	return nil
+}
+
+// setHyperlink sets the Hyperlink field to the link target of the cell, showing rowVal
+func (t *TypeReader[T]) setHyperlink(v reflect.Value, col string, rowVal string) error {
+	link := Hyperlink{Text: rowVal}
+	if cell, exists := t.currentCell(col); exists {
+		var err error
+		if link.URL, err = t.file.GetCellHyperLink(t.sheet, cell); err != nil {
+			return newNonIndexedRowError(fmt.Errorf("error reading hyperlink: %v, column: '%s'", err, col))
+		}
+	}
+	if v.Kind() == reflect.Ptr {
+		v.Set(reflect.ValueOf(&link))
+		return nil
+	}
+	v.Set(reflect.ValueOf(link))
+	return nil
+}
+
+// setImage sets the Image field to the image anchored at the cell, leaving it empty if there is none
+func (t *TypeReader[T]) setImage(v reflect.Value, col string) error {
+	cell, exists := t.currentCell(col)
+	if !exists {
+		return nil
+	}
+	image, ok := t.images[cell]
+	if !ok {
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		v.Set(reflect.ValueOf(&image))
+		return nil
+	}
+	v.Set(reflect.ValueOf(image))
+	return nil
+}
+
+// currentCell returns the name of the cell of col in the row being read, exists is false if the column is not present
+func (t *TypeReader[T]) currentCell(col string) (cell string, exists bool) {
+	headerIndex, exists := t.headersToIndex[col]
+	if !exists {
+		return "", false
+	}
+	cell, err := excelize.CoordinatesToCellName(t.options.startColumn()+headerIndex, t.rowNumbers[t.nextRowToRead-1])
+	return cell, err == nil
 }
 
 func (t *TypeReader[T]) analyzeType() (err error) {
@@ -237,24 +389,66 @@ func (t *TypeReader[T]) analyzeType() (err error) {
 		}
 	}()
 	var toRead T
-	info, err := analyzeType(reflect.TypeOf(toRead))
+	info, err := cachedTypeInfo(reflect.TypeOf(toRead))
 	if err != nil {
 		return err
 	}
 	t.typeInfo = info
-	t.rows, err = t.file.GetDefaultSheetRows()
+	if generated, ok := any(&toRead).(GexRowReader); ok {
+		fields := generated.GexelizerFields()
+		if positions, ok := generatedFieldPositions(info, fields); ok {
+			t.fieldPositions = positions
+			t.fieldColumns = make([]string, len(fields))
+			for i, col := range info.orderedColumns {
+				t.fieldColumns[positions[i]] = col
+			}
+			t.generatedValues = make([]string, len(fields))
+		}
+	}
+	if t.options.Table != "" {
+		sheet, ref, err := t.file.FindTable(t.options.Table)
+		if err != nil {
+			return err
+		}
+		t.options.Sheet = sheet
+		if err := t.options.setRange(ref); err != nil {
+			return err
+		}
+	}
+	if t.options.DefinedName != "" {
+		sheet, ref, err := t.file.FindDefinedName(t.options.DefinedName)
+		if err != nil {
+			return err
+		}
+		t.options.Sheet = sheet
+		if strings.Contains(ref, ":") {
+			err = t.options.setRange(ref)
+		} else {
+			err = t.options.setStartCell(ref)
+		}
+		if err != nil {
+			return fmt.Errorf("defined name %s: %w", t.options.DefinedName, err)
+		}
+	}
+	t.sheet = t.options.Sheet
+	if t.sheet == "" {
+		t.sheet = t.file.GetDefaultSheet()
+	}
+	headerIndex, err := t.loadRows()
 	if err != nil {
 		return err
 	}
-	//trim empty rows or rows with one column from the beginning and end
-	if t.options.TrimEmptyRows {
-		t.rows = trimEmptyRows(t.rows)
-	}
-	if len(t.rows) <= int(t.options.HeaderRow) {
-		return fmt.Errorf("header row is out of bounds")
+	if err := t.mapHeaders(t.rows[headerIndex]); err != nil {
+		return err
 	}
-	t.headers = make([]string, len(t.rows[t.options.HeaderRow]))
-	for i, header := range t.rows[t.options.HeaderRow] {
+	return t.readSheetValues(headerIndex)
+}
+
+// mapHeaders maps the columns of T to the index of their header, by name, alias or label,
+// and checks that the required columns are present
+func (t *TypeReader[T]) mapHeaders(headerRow []string) error {
+	t.headers = make([]string, len(headerRow))
+	for i, header := range headerRow {
 		t.headers[i] = strings.TrimSpace(strings.ToLower(header))
 	}
 	t.headersToIndex = make(map[string]int, len(t.headers))
@@ -268,31 +462,268 @@ func (t *TypeReader[T]) analyzeType() (err error) {
 		lowerName := strings.ToLower(fi.name)
 		t.headersToIndex[lowerName] = i
 	}
+	//Accept the labels and translations columns are written with
+	for _, col := range t.typeInfo.orderedColumns {
+		if _, exists := t.headersToIndex[col]; exists {
+			continue
+		}
+		for _, label := range t.options.acceptedLabels(col, t.typeInfo.nameToField[col]) {
+			if index, exists := t.headersToIndex[strings.TrimSpace(strings.ToLower(label))]; exists {
+				t.headersToIndex[col] = index
+				break
+			}
+		}
+	}
 	//Check if all required fields are present
+	t.totalsColumns = nil
 	for _, col := range t.typeInfo.orderedColumns {
 		fi := t.typeInfo.nameToField[col]
-		if _, exists := t.headersToIndex[col]; fi.required && !exists {
+		_, exists := t.headersToIndex[col]
+		if fi.required && !exists && t.expectsColumn(fi) {
 			return fmt.Errorf("required field %s is missing", col)
 		}
+		if exists && t.isTotalsColumn(fi) {
+			t.totalsColumns = append(t.totalsColumns, col)
+		}
+	}
+	return nil
+}
+
+// loadRows reads the rows of the sheet within the range of the options and returns the index of the header row
+func (t *TypeReader[T]) loadRows() (int, error) {
+	var err error
+	t.rows, err = t.file.GetSheetRows(t.sheet)
+	if err != nil {
+		return 0, err
+	}
+	t.rows = cropRows(t.rows, t.options)
+	t.rowNumbers = make([]int, len(t.rows))
+	for i := range t.rowNumbers {
+		t.rowNumbers[i] = i + 1
+	}
+	//trim empty rows or rows with one column from the beginning and end
+	if t.options.TrimEmptyRows {
+		var trimmed int
+		t.rows, trimmed = trimEmptyRows(t.rows)
+		t.rowNumbers = t.rowNumbers[trimmed : trimmed+len(t.rows)]
+		t.removeEmptyRows()
+	}
+	//The header is the first row left at or after HeaderRow, data keeps the same distance from it
+	headerIndex := t.rowIndexFrom(t.options.HeaderRow)
+	if headerIndex >= len(t.rows) {
+		return 0, fmt.Errorf("header row is out of bounds")
 	}
-	t.nextRowToRead = t.options.DataStartRow
+	return headerIndex, nil
+}
+
+// readSheetValues moves to the first data row below the header row and reads the raw values, formulas
+// and images of the sheet the columns need
+func (t *TypeReader[T]) readSheetValues(headerIndex int) error {
+	dataStartRow := uint(t.rowNumbers[headerIndex]) + t.options.DataStartRow - t.options.HeaderRow
+	t.nextRowToRead = uint(t.rowIndexFrom(dataStartRow))
 
 	// normalize matrix width
 	for i := 0; i < len(t.rows); i++ {
-		if t.options.TrimEmptyRows && len(t.rows[i]) == 0 {
-			//Remove empty rows
-			t.rows = append(t.rows[:i], t.rows[i+1:]...)
-			i--
-			continue
-		}
 		if len(t.rows[i]) < len(t.headers) {
 			t.rows[i] = append(t.rows[i], make([]string, len(t.headers)-len(t.rows[i]))...)
 		}
 	}
+	if err := t.readRawValues(); err != nil {
+		return err
+	}
+	if err := t.readFormulas(); err != nil {
+		return err
+	}
+	return t.readImages()
+}
+
+// readRawValues replaces the formatted values of number, bool and date columns with the raw cell values,
+// so formatted numbers still parse and native dates are read as RFC 3339, or as 2006-01-02 for Date
+func (t *TypeReader[T]) readRawValues() error {
+	var rawColumns []int
+	var rawFields []fieldInfo
+	for _, col := range t.typeInfo.orderedColumns {
+		fi := t.typeInfo.nameToField[col]
+		if index, exists := t.headersToIndex[col]; exists && fi.readRaw && !fi.formula {
+			rawColumns = append(rawColumns, index)
+			rawFields = append(rawFields, fi)
+		}
+	}
+	if len(rawColumns) == 0 {
+		return nil
+	}
+	rawRows, err := t.file.GetSheetRawRows(t.sheet)
+	if err != nil {
+		return err
+	}
+	date1904, err := t.file.UsesDate1904()
+	if err != nil {
+		return err
+	}
+	for i := int(t.nextRowToRead); i < len(t.rows); i++ {
+		if t.rowNumbers[i] > len(rawRows) {
+			break
+		}
+		rawRow := rawRows[t.rowNumbers[i]-1]
+		for k, j := range rawColumns {
+			column := t.options.startColumn() - 1 + j
+			if column >= len(rawRow) || rawRow[column] == "" {
+				continue
+			}
+			raw := rawRow[column]
+			if fi := rawFields[k]; fi.date || fi.dateTime {
+				serial, err := strconv.ParseFloat(raw, 64)
+				if err != nil {
+					//Dates written as text keep their value
+					continue
+				}
+				date, err := excelize.ExcelDateToTime(serial, date1904)
+				if err != nil {
+					continue
+				}
+				if fi.date {
+					raw = date.Format("2006-01-02")
+				} else {
+					//The serial holds the wall clock of the location of the options
+					location := t.options.location()
+					date = time.Date(date.Year(), date.Month(), date.Day(), date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), location)
+					raw = date.Format(time.RFC3339Nano)
+				}
+			}
+			t.rows[i][j] = raw
+		}
+	}
 	return nil
 }
 
-func trimEmptyRows(rows [][]string) [][]string {
+// readImages collects the images of the sheet, read into the Image fields of the cells they are anchored at
+func (t *TypeReader[T]) readImages() error {
+	t.images = nil
+	imageColumns := false
+	for _, col := range t.typeInfo.orderedColumns {
+		if _, exists := t.headersToIndex[col]; exists && t.typeInfo.nameToField[col].image {
+			imageColumns = true
+		}
+	}
+	if !imageColumns {
+		return nil
+	}
+	var err error
+	t.images, err = t.file.GetSheetImages(t.sheet)
+	return err
+}
+
+// hasImage reports whether the cell of the Image column col in the row being read holds an image,
+// so cells holding only an image are not read as empty
+func (t *TypeReader[T]) hasImage(col string, info fieldInfo) bool {
+	if !info.image || len(t.images) == 0 {
+		return false
+	}
+	cell, exists := t.currentCell(col)
+	if !exists {
+		return false
+	}
+	_, ok := t.images[cell]
+	return ok
+}
+
+// readFormulas replaces the cached values of formula columns with the formula text, e.g. "=SUM(B2:B10)",
+// and with Recalculate, the values of other formula cells with freshly calculated ones
+func (t *TypeReader[T]) readFormulas() error {
+	formulaColumns := make(map[int]bool)
+	for _, col := range t.typeInfo.orderedColumns {
+		if index, exists := t.headersToIndex[col]; exists && t.typeInfo.nameToField[col].formula {
+			formulaColumns[index] = true
+		}
+	}
+	if len(formulaColumns) == 0 && !t.options.Recalculate {
+		return nil
+	}
+	for i := int(t.nextRowToRead); i < len(t.rows); i++ {
+		for j := range t.headers {
+			if !formulaColumns[j] && !t.options.Recalculate {
+				continue
+			}
+			cell, err := excelize.CoordinatesToCellName(t.options.startColumn()+j, t.rowNumbers[i])
+			if err != nil {
+				return err
+			}
+			formula, err := t.file.GetCellFormula(t.sheet, cell)
+			if err != nil {
+				return err
+			}
+			if formula == "" {
+				continue
+			}
+			if formulaColumns[j] {
+				t.rows[i][j] = "=" + formula
+				continue
+			}
+			value, err := t.file.CalcCellValue(t.sheet, cell)
+			if err != nil {
+				//Formulas excelize cannot calculate keep their cached value
+				if t.rows[i][j] != "" {
+					continue
+				}
+				return fmt.Errorf("error calculating %s!%s: %w", t.sheet, cell, err)
+			}
+			t.rows[i][j] = value
+		}
+	}
+	return nil
+}
+
+// cropRows keeps only the cells inside the table bounds of the options
+func cropRows(rows [][]string, options Options) [][]string {
+	if options.EndRow > 0 && len(rows) > int(options.EndRow) {
+		rows = rows[:options.EndRow]
+	}
+	startColumn := options.startColumn()
+	if startColumn == 1 && options.EndColumn == 0 {
+		return rows
+	}
+	for i, row := range rows {
+		if len(row) < startColumn {
+			rows[i] = nil
+			continue
+		}
+		row = row[startColumn-1:]
+		if options.EndColumn > 0 && len(row) > int(options.EndColumn)-startColumn+1 {
+			row = row[:int(options.EndColumn)-startColumn+1]
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+// rowIndexFrom returns the index of the first row with sheet row number at or after rowNumber
+func (t *TypeReader[T]) rowIndexFrom(rowNumber uint) int {
+	for i, number := range t.rowNumbers {
+		if uint(number) >= rowNumber {
+			return i
+		}
+	}
+	return len(t.rows)
+}
+
+// removeEmptyRows removes empty rows along with their row numbers
+func (t *TypeReader[T]) removeEmptyRows() {
+	rows := make([][]string, 0, len(t.rows))
+	rowNumbers := make([]int, 0, len(t.rowNumbers))
+	for i, row := range t.rows {
+		if len(row) == 0 {
+			continue
+		}
+		rows = append(rows, row)
+		rowNumbers = append(rowNumbers, t.rowNumbers[i])
+	}
+	t.rows = rows
+	t.rowNumbers = rowNumbers
+}
+
+// trimEmptyRows trims empty rows, or rows with one column, from the beginning and the end
+// it returns the remaining rows and the number of rows trimmed from the beginning
+func trimEmptyRows(rows [][]string) ([][]string, int) {
 	//Scan rows from the beginning to the end and trim empty rows
 	for i := 0; i < len(rows); i++ {
 		if len(rows[i]) == 0 {
@@ -327,26 +758,52 @@ func trimEmptyRows(rows [][]string) [][]string {
 		trimFromEnd++
 	}
 	if trimFromBeginning+trimFromEnd > len(rows) {
-		return rows
+		return rows, 0
 	}
-	return rows[trimFromBeginning : len(rows)-trimFromEnd]
+	return rows[trimFromBeginning : len(rows)-trimFromEnd], trimFromBeginning
 }
 
 func (t *TypeReader[T]) readSingleWithoutSlice(row []string, v reflect.Value) error {
 	for i := 0; i < len(t.typeInfo.orderedColumns); i++ {
 		col := t.typeInfo.orderedColumns[i]
 		fi := t.typeInfo.nameToField[col]
+		rowVal, err := t.cellValue(col, fi, row)
+		if err != nil {
+			return err
+		}
+		// Empty values are skipped before touching the field, so nil parent pointers are only allocated for values,
+		// like the generated readers do
+		if rowVal == "" && !t.hasImage(col, fi) {
+			continue
+		}
 		fv, err := fieldByIndexInit(v, fi.index)
 		if err != nil {
 			continue
 		}
-		if _, err = t.setParsedValue(fv, col, fi, row); err != nil {
+		if err = t.setValue(fv, col, rowVal); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// readGenerated reads the row through the generated GexelizerReadRow of T, without reflection
+func (t *TypeReader[T]) readGenerated(row []string, toRead *T) error {
+	for i, col := range t.typeInfo.orderedColumns {
+		rowVal, err := t.cellValue(col, t.typeInfo.nameToField[col], row)
+		if err != nil {
+			return err
+		}
+		t.generatedValues[t.fieldPositions[i]] = rowVal
+	}
+	err := any(toRead).(GexRowReader).GexelizerReadRow(t.generatedValues)
+	var fieldErr FieldError
+	if errors.As(err, &fieldErr) && fieldErr.Index >= 0 && fieldErr.Index < len(t.fieldColumns) {
+		return newNonIndexedRowError(fmt.Errorf("error parsing cell value: %v, column: '%s'", fieldErr.Err, t.fieldColumns[fieldErr.Index]))
+	}
+	return err
+}
+
 func (t *TypeReader[T]) readNonSliceField(v reflect.Value, fi fieldInfo, col string, row []string) (toContinue bool, err error) {
 	// In case of possible nil pointer, we need to create the parent struct instance
 	var parent, grandParent reflect.Value
@@ -2,6 +2,12 @@ package gexelizer
 
 import (
 	"bytes"
+	"github.com/xuri/excelize/v2"
+	"image"
+	"image/color"
+	"image/png"
+	"path/filepath"
+	"regexp"
 	"testing"
 	"time"
 )
@@ -258,3 +264,487 @@ func TestWriteAndReadComplexWithDefault(t *testing.T) {
 		}
 	}
 }
+
+func TestWriteAndReadEncrypted(t *testing.T) {
+	type row struct {
+		Name   string `gex:"column:name"`
+		Amount float64
+	}
+	data := []row{{Name: "John", Amount: 10.5}, {Name: "Jane", Amount: 20}}
+	buffer, err := WriteExcelToBuffer(data, WithPassword("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted := buffer.Bytes()
+	if _, err := excelize.OpenReader(bytes.NewReader(encrypted)); err == nil {
+		t.Fatal("expected the written file to be encrypted")
+	}
+	if _, err := ReadExcel[row](bytes.NewReader(encrypted)); err == nil {
+		t.Fatal("expected reading without password to fail")
+	}
+	if _, err := ReadExcel[row](bytes.NewReader(encrypted), WithPassword("wrong")); err == nil {
+		t.Fatal("expected reading with a wrong password to fail")
+	}
+	read, err := ReadExcel[row](bytes.NewReader(encrypted), WithPassword("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 2 || read[0] != data[0] || read[1] != data[1] {
+		t.Fatalf("unexpected rows %+v", read)
+	}
+
+	filename := filepath.Join(t.TempDir(), "encrypted.xlsx")
+	if err := WriteToFile(filename, data, WithPassword("secret")); err != nil {
+		t.Fatal(err)
+	}
+	read, err = ReadExcelFile[row](filename, WithPassword("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 2 {
+		t.Fatalf("expected 2 rows, got %+v", read)
+	}
+	if _, err := ReadXLSExcel[row](bytes.NewReader(encrypted), WithPassword("secret")); err == nil {
+		t.Fatal("expected password protected xls to be rejected")
+	}
+}
+
+func TestReadExcel_StopAndSkipRows(t *testing.T) {
+	type row struct {
+		Name   string  `gex:"column:name"`
+		Amount float64 `gex:"column:amount"`
+	}
+	newFile := func(janeAmount string) []byte {
+		file := excelize.NewFile()
+		rows := [][]any{
+			{"Name", "Amount"},
+			{"John", "10"},
+			{"Subtotal", "10"},
+			{},
+			{"Jane", janeAmount},
+			{"Total", "30"},
+			{"Generated by", "reports"},
+		}
+		for i, r := range rows {
+			cell, _ := excelize.CoordinatesToCellName(1, i+1)
+			_ = file.SetSheetRow("Sheet1", cell, &r)
+		}
+		buffer, err := file.WriteToBuffer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return buffer.Bytes()
+	}
+	opts := []Option{
+		WithSkipRow(FirstCellEquals("subtotal")),
+		WithStopAt(FirstCellMatches(regexp.MustCompile(`^Total$`))),
+	}
+	read, err := ReadExcel[row](bytes.NewReader(newFile("20")), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []row{{Name: "John", Amount: 10}, {Name: "Jane", Amount: 20}}
+	if len(read) != len(expected) || read[0] != expected[0] || read[1] != expected[1] {
+		t.Fatalf("expected %+v, got %+v", expected, read)
+	}
+	if _, err := ReadExcel[row](bytes.NewReader(newFile("20"))); err == nil {
+		t.Fatal("expected footer rows to fail parsing without predicates")
+	}
+	_, err = ReadExcel[row](bytes.NewReader(newFile("twenty")), opts...)
+	rowErr, ok := err.(RowError)
+	if !ok {
+		t.Fatalf("expected a row error, got %v", err)
+	}
+	if rowErr.RowNumber != 5 {
+		t.Fatalf("expected the error on sheet row 5, got %d", rowErr.RowNumber)
+	}
+}
+
+func TestWriteAndReadTable(t *testing.T) {
+	type row struct {
+		Name   string  `gex:"column:name"`
+		Amount float64 `gex:"column:amount"`
+		Note   string  `gex:"column:note,omitempty"`
+	}
+	data := []row{{Name: "John", Amount: 10}, {Name: "Jane", Amount: 20}}
+	file, err := WriteExcelSheet(nil, "Orders", data, WithStartCell("B3"), WithTable("tblOrders"), WithTableStyle("TableStyleMedium2"),
+		WithRemoveEmptyColumns(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables, err := file.GetBaseFile().GetTables("Orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 1 || tables[0].Range != "B3:C5" || tables[0].StyleName != "TableStyleMedium2" {
+		t.Fatalf("unexpected tables %+v", tables)
+	}
+	//Surround the table with content that is not part of it
+	base := file.GetBaseFile()
+	_ = base.SetCellValue("Orders", "A1", "Orders report")
+	_ = base.SetCellValue("Orders", "B6", "Total")
+	_ = base.SetCellValue("Orders", "D4", "side note")
+	buffer, err := file.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadExcel[row](bytes.NewReader(buffer.Bytes()), WithTable("TBLORDERS"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != len(data) || read[0] != data[0] || read[1] != data[1] {
+		t.Fatalf("expected %+v, got %+v", data, read)
+	}
+	if _, err := ReadExcel[row](bytes.NewReader(buffer.Bytes()), WithTable("missing")); err == nil {
+		t.Fatal("expected a missing table to be reported")
+	}
+}
+
+func TestWriteAndReadDefinedName(t *testing.T) {
+	type row struct {
+		Name   string  `gex:"column:name"`
+		Amount float64 `gex:"column:amount"`
+	}
+	data := []row{{Name: "John", Amount: 10}, {Name: "Jane", Amount: 20}}
+	file, err := WriteExcelSheet(nil, "Input's data", data, WithStartCell("C4"), WithDefinedName("InputData"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := file.GetBaseFile()
+	names := base.GetDefinedName()
+	if len(names) != 1 || names[0].RefersTo != "'Input''s data'!$C$4:$D$6" {
+		t.Fatalf("unexpected defined names %+v", names)
+	}
+	//Content below the named range is not read
+	_ = base.SetCellValue("Input's data", "C7", "Total")
+	_ = base.SetDefinedName(&excelize.DefinedName{Name: "InputStart", RefersTo: "'Input''s data'!$C$4"})
+	buffer, err := file.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadExcel[row](bytes.NewReader(buffer.Bytes()), WithDefinedName("inputdata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != len(data) || read[0] != data[0] || read[1] != data[1] {
+		t.Fatalf("expected %+v, got %+v", data, read)
+	}
+	read, err = ReadExcel[row](bytes.NewReader(buffer.Bytes()), WithDefinedName("InputStart"), WithStopAt(FirstCellEquals("total")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != len(data) {
+		t.Fatalf("expected %+v, got %+v", data, read)
+	}
+	if _, err := ReadExcel[row](bytes.NewReader(buffer.Bytes()), WithDefinedName("missing")); err == nil {
+		t.Fatal("expected a missing defined name to be reported")
+	}
+}
+
+func TestWriteAndReadFormulas(t *testing.T) {
+	type row struct {
+		Name   string  `gex:"column:name"`
+		Amount float64 `gex:"column:amount"`
+		Double Formula `gex:"column:double"`
+		Note   string  `gex:"column:note,formula"`
+	}
+	data := []row{
+		{Name: "John", Amount: 10, Double: "=B2*2", Note: "=A2&\"!\""},
+		{Name: "Jane", Amount: 20, Double: "=B3*2", Note: "plain"},
+		{Name: "Total", Amount: 30, Double: "SUM(C2:C3)"},
+	}
+	buffer, err := WriteExcelToBuffer(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := buffer.Bytes()
+	file, err := excelize.OpenReader(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if formula, _ := file.GetCellFormula("Sheet1", "C4"); formula != "SUM(C2:C3)" {
+		t.Fatalf("expected a formula in C4, got %q", formula)
+	}
+	if formula, _ := file.GetCellFormula("Sheet1", "D3"); formula != "" {
+		t.Fatalf("expected a plain value in D3, got formula %q", formula)
+	}
+
+	read, err := ReadExcel[row](bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[2].Double = "=SUM(C2:C3)"
+	if len(read) != len(data) {
+		t.Fatalf("expected %d rows, got %+v", len(data), read)
+	}
+	for i := range data {
+		if read[i] != data[i] {
+			t.Fatalf("expected %+v, got %+v", data[i], read[i])
+		}
+	}
+
+	type calculated struct {
+		Name   string  `gex:"column:name"`
+		Double float64 `gex:"column:double"`
+		Note   string  `gex:"column:note"`
+	}
+	values, err := ReadExcel[calculated](bytes.NewReader(raw), WithRecalculate(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []calculated{{Name: "John", Double: 20, Note: "John!"}, {Name: "Jane", Double: 40, Note: "plain"}, {Name: "Total", Double: 60}}
+	for i := range expected {
+		if values[i] != expected[i] {
+			t.Fatalf("expected %+v, got %+v", expected[i], values[i])
+		}
+	}
+}
+
+func TestWriteAndReadHyperlinks(t *testing.T) {
+	type row struct {
+		SKU     Hyperlink  `gex:"column:sku"`
+		Details *Hyperlink `gex:"column:details"`
+		Name    string     `gex:"column:name"`
+	}
+	data := []row{
+		{SKU: Hyperlink{URL: "https://admin.example.com/products/1", Text: "SKU-1"}, Details: &Hyperlink{URL: "Details!A2", Text: "details"}, Name: "Chair"},
+		{SKU: Hyperlink{URL: "https://admin.example.com/products/2"}, Name: "Table"},
+	}
+	buffer, err := WriteExcelToBuffer(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := buffer.Bytes()
+	file, err := excelize.OpenReader(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := file.GetCellValue("Sheet1", "A3"); value != "https://admin.example.com/products/2" {
+		t.Fatalf("expected the URL to be shown without text, got %q", value)
+	}
+	if style, _ := file.GetCellStyle("Sheet1", "A2"); style == 0 {
+		t.Fatal("expected hyperlink cells to be styled")
+	}
+
+	read, err := ReadExcel[row](bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 2 {
+		t.Fatalf("expected 2 rows, got %+v", read)
+	}
+	if read[0].SKU != data[0].SKU || read[0].Details == nil || *read[0].Details != *data[0].Details {
+		t.Fatalf("expected %+v, got %+v", data[0], read[0])
+	}
+	expected := Hyperlink{URL: data[1].SKU.URL, Text: data[1].SKU.URL}
+	if read[1].SKU != expected || read[1].Details != nil || read[1].Name != "Table" {
+		t.Fatalf("expected %+v, got %+v", data[1], read[1])
+	}
+}
+
+func TestWriteAndReadImages(t *testing.T) {
+	type row struct {
+		Name      string  `gex:"column:name"`
+		Price     float64 `gex:"column:price"`
+		Thumbnail Image   `gex:"column:thumbnail"`
+		Photo     *Image  `gex:"column:photo,omitempty"`
+	}
+	thumbnail := &bytes.Buffer{}
+	picture := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	picture.Set(1, 1, color.RGBA{R: 255, A: 255})
+	if err := png.Encode(thumbnail, picture); err != nil {
+		t.Fatal(err)
+	}
+	data := []row{
+		{Name: "Chair", Price: 40, Thumbnail: Image{Data: thumbnail.Bytes()}},
+		{Name: "Table", Price: 120},
+	}
+	buffer, err := WriteExcelToBuffer(data, WithImageRowHeight(60))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := buffer.Bytes()
+	file, err := excelize.OpenReader(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height, _ := file.GetRowHeight("Sheet1", 2); height != 60 {
+		t.Fatalf("expected the image row to be 60 points high, got %v", height)
+	}
+	if height, _ := file.GetRowHeight("Sheet1", 3); height == 60 {
+		t.Fatal("expected rows without images to keep their height")
+	}
+	if header, _ := file.GetCellValue("Sheet1", "D1"); header != "" {
+		t.Fatalf("expected the empty photo column to be removed, got %q", header)
+	}
+
+	var thumbnailCells []string
+	read, err := ReadExcel[row](bytes.NewReader(raw), WithSkipRow(func(row []string) bool {
+		thumbnailCells = append(thumbnailCells, row[2])
+		return false
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(thumbnailCells) != 2 || thumbnailCells[0] != "" || thumbnailCells[1] != "" {
+		t.Fatalf("expected predicates to see the empty thumbnail cells, got %q", thumbnailCells)
+	}
+	if len(read) != 2 || read[0].Name != "Chair" || read[1].Name != "Table" {
+		t.Fatalf("unexpected rows %+v", read)
+	}
+	if !bytes.Equal(read[0].Thumbnail.Data, thumbnail.Bytes()) || read[0].Thumbnail.Ext != ".png" {
+		t.Fatalf("expected the thumbnail to be read back, got %d bytes with extension %q", len(read[0].Thumbnail.Data), read[0].Thumbnail.Ext)
+	}
+	if read[1].Thumbnail.Data != nil {
+		t.Fatal("expected no image in the second row")
+	}
+	if _, err := WriteExcelToBuffer(data, WithImageRowHeight(500)); err == nil {
+		t.Fatal("expected an invalid row height to be rejected")
+	}
+}
+
+func TestWriteAndReadNativeDates(t *testing.T) {
+	type row struct {
+		Name     string     `gex:"column:name"`
+		Created  time.Time  `gex:"column:created"`
+		Born     Date       `gex:"column:born"`
+		Reviewed *time.Time `gex:"column:reviewed,numfmt:dd.mm.yyyy"`
+		Amount   float64    `gex:"column:amount,numfmt:4"`
+	}
+	created := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	reviewed := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	data := []row{
+		{Name: "John", Created: created, Born: "1990-05-17", Reviewed: &reviewed, Amount: 1234.5},
+		{Name: "Jane", Created: created.Add(36 * time.Hour), Born: "2001-12-31", Amount: 10},
+	}
+	buffer, err := WriteExcelToBuffer(data, WithDateTimeFormat("dd/mm/yyyy hh:mm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := buffer.Bytes()
+	file, err := excelize.OpenReader(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedCells := map[string]string{"B2": "15/03/2024 10:30", "C2": "1990-05-17", "D2": "01.04.2024", "E2": "1,234.50"}
+	for cell, expected := range expectedCells {
+		if value, _ := file.GetCellValue("Sheet1", cell); value != expected {
+			t.Fatalf("expected %s at %s, got %q", expected, cell, value)
+		}
+	}
+	if cellType, _ := file.GetCellType("Sheet1", "B2"); cellType == excelize.CellTypeSharedString || cellType == excelize.CellTypeInlineString {
+		t.Fatal("expected dates to be written as numbers")
+	}
+
+	read, err := ReadExcel[row](bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != len(data) {
+		t.Fatalf("expected %d rows, got %+v", len(data), read)
+	}
+	for i := range data {
+		if !read[i].Created.Equal(data[i].Created) || read[i].Born != data[i].Born || read[i].Amount != data[i].Amount {
+			t.Fatalf("expected %+v, got %+v", data[i], read[i])
+		}
+	}
+	if read[0].Reviewed == nil || !read[0].Reviewed.Equal(reviewed) || read[1].Reviewed != nil {
+		t.Fatalf("unexpected reviewed dates %v and %v", read[0].Reviewed, read[1].Reviewed)
+	}
+
+	//Workbooks counting dates from 1904 store other serial numbers for the same dates
+	date1904 := true
+	writer := NewExcelizeWriter()
+	if err := writer.GetBaseFile().SetWorkbookProps(&excelize.WorkbookPropsOptions{Date1904: &date1904}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WriteExcelSheet(writer, "Sheet1", data); err != nil {
+		t.Fatal(err)
+	}
+	if serial, _ := writer.GetBaseFile().GetCellValue("Sheet1", "C2", excelize.Options{RawCellValue: true}); serial != "31548" {
+		t.Fatalf("expected the 1904 serial number of the birth date, got %s", serial)
+	}
+	buffer, err = writer.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err = ReadExcel[row](buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != len(data) || !read[0].Created.Equal(created) || read[0].Born != data[0].Born {
+		t.Fatalf("expected the dates of a 1904 workbook to be read, got %+v", read)
+	}
+}
+
+func TestWriteAndReadDatesInLocation(t *testing.T) {
+	type row struct {
+		Created  time.Time  `gex:"column:created"`
+		Reviewed *time.Time `gex:"column:reviewed"`
+	}
+	dubai := time.FixedZone("Dubai", 4*60*60)
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, dubai)
+	reviewed := time.Date(2024, 5, 2, 1, 30, 0, 0, dubai)
+	data := []row{{Created: created, Reviewed: &reviewed}}
+	for _, test := range []struct {
+		location *time.Location
+		created  string
+		reviewed string
+	}{
+		{nil, "2024-05-01 06:00:00", "2024-05-01 21:30:00"},
+		{dubai, "2024-05-01 10:00:00", "2024-05-02 01:30:00"},
+	} {
+		buffer, err := WriteExcelToBuffer(data, WithLocation(test.location))
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw := buffer.Bytes()
+		file, err := excelize.OpenReader(bytes.NewReader(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value, _ := file.GetCellValue("Sheet1", "A2"); value != test.created {
+			t.Fatalf("expected %s in %v, got %s", test.created, test.location, value)
+		}
+		if value, _ := file.GetCellValue("Sheet1", "B2"); value != test.reviewed {
+			t.Fatalf("expected %s in %v, got %s", test.reviewed, test.location, value)
+		}
+		read, err := ReadExcel[row](bytes.NewReader(raw), WithLocation(test.location))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(read) != 1 || !read[0].Created.Equal(created) || read[0].Reviewed == nil || !read[0].Reviewed.Equal(reviewed) {
+			t.Fatalf("expected the same instants in %v, got %+v", test.location, read)
+		}
+	}
+}
+
+func TestReadExcel_NilParentPointers(t *testing.T) {
+	type address struct {
+		Street string `gex:"column:street"`
+		City   string `gex:"column:city"`
+	}
+	type row struct {
+		Name    string   `gex:"column:name"`
+		Age     int      `gex:"column:age"`
+		Address *address `gex:"column:address"`
+	}
+	data := []row{
+		{Name: "John", Age: 30, Address: &address{Street: "Main"}},
+		{Name: "Jane", Age: 25},
+	}
+	buffer, err := WriteExcelToBuffer(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadExcel[row](buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 2 || read[0].Address == nil || *read[0].Address != *data[0].Address {
+		t.Fatalf("expected the address of John to be read, got %+v", read)
+	}
+	if read[1].Address != nil {
+		t.Fatalf("expected the empty address of Jane to stay nil, got %+v", read[1].Address)
+	}
+}
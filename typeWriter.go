@@ -5,8 +5,12 @@ import (
 	"fmt"
 	"github.com/xuri/excelize/v2"
 	"io"
+	"math"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 type TypeWriter[T any] struct {
@@ -14,12 +18,42 @@ type TypeWriter[T any] struct {
 	typeInfo             typeInfo
 	headers              []string
 	columnContainsValues []bool
+	// columnLengths holds the length of the longest value written to every header, for AutoFitColumns
+	columnLengths []int
+	// parentColumns marks the headers outside of the slice of T, nil if T has no slice
+	parentColumns []bool
+	// formulaColumns marks the headers whose "=" prefixed string values are written as formulas, nil if there are none
+	formulaColumns []bool
+
+	// set when T has a generated row writer covering every column
+	fieldPositions  []int
+	generatedValues []any
 
 	nextRowToWrite uint
-	options        *Options
+	// firstDataRow is the first data row written by the writer, rows above it are kept as they are when appending
+	firstDataRow uint
+	options      Options
+	// sheet the writer writes into, the file may be shared with writers of other sheets
+	sheet string
+	// visibleColumns holds the indexes of the headers left after removing empty columns
+	visibleColumns []int
+	finalized      bool
+	// columnOffsets holds the column of every header relative to the start column when appending to a sheet
+	// whose header order differs, nil if headers are written in order
+	columnOffsets []int
+	// sheetWidth is the number of columns from the start column when columnOffsets is set
+	sheetWidth int
+	// child is the sheet slice elements are written to, nil without ChildSheet
+	child *childSheet
+	// series tracks the sheets rolled over to, nil while writing the first sheet
+	series *sheetSeries
+	// subtotals groups the written rows, nil without SubtotalGroup
+	subtotals *subtotalGroups
+	// template marks required headers, see WriteTemplate
+	template bool
 }
 
-func WriteToFile[T any](filename string, data []T, opts ...Options) error {
+func WriteToFile[T any](filename string, data []T, opts ...Option) error {
 	tw, err := NewTypeWriter[T](opts...)
 	if err != nil {
 		return err
@@ -31,7 +65,7 @@ func WriteToFile[T any](filename string, data []T, opts ...Options) error {
 	return tw.WriteToFile(filename)
 }
 
-func WriteExcel[T any](writer io.Writer, data []T, opts ...Options) error {
+func WriteExcel[T any](writer io.Writer, data []T, opts ...Option) error {
 	tw, err := NewTypeWriter[T](opts...)
 	if err != nil {
 		return err
@@ -44,7 +78,7 @@ func WriteExcel[T any](writer io.Writer, data []T, opts ...Options) error {
 	return err
 }
 
-func WriteExcelToBuffer[T any](data []T, opts ...Options) (*bytes.Buffer, error) {
+func WriteExcelToBuffer[T any](data []T, opts ...Option) (*bytes.Buffer, error) {
 	tw, err := NewTypeWriter[T](opts...)
 	if err != nil {
 		return nil, err
@@ -56,32 +90,49 @@ func WriteExcelToBuffer[T any](data []T, opts ...Options) (*bytes.Buffer, error)
 	return tw.WriteToBuffer()
 }
 
-func WriteExcelSheet[T any](existingFileWriter ExcelFileWriter, sheetName string, data []T, opts ...Options) (ExcelFileWriter, error) {
+// WriteExcelSheet writes data to sheetName of existingFileWriter, or of a new file if it is nil, and returns the file.
+// Empty columns are kept unless the options set RemoveEmptyColumns
+func WriteExcelSheet[T any](existingFileWriter ExcelFileWriter, sheetName string, data []T, opts ...Option) (ExcelFileWriter, error) {
 	if existingFileWriter == nil {
 		existingFileWriter = NewExcelizeWriter()
 	}
-	if len(opts) == 0 {
-		opts = append(opts, *DefaultOptions())
-	}
-	opts[0].File = existingFileWriter
+	//Copy before appending, so the caller's slice is never written to
+	opts = append(opts[:len(opts):len(opts)], WithFile(existingFileWriter), WithSheet(sheetName))
 	tw, err := NewTypeWriter[T](opts...)
 	if err != nil {
 		return nil, err
 	}
-	if err := tw.file.SetDefaultSheet(sheetName); err != nil {
+	tw.options.KeepEmptyColumns = !tw.options.RemoveEmptyColumns
+	if err = tw.Write(data); err != nil {
 		return nil, err
 	}
-	if err = tw.Write(data); err != nil {
+	if err = tw.finalize(); err != nil {
 		return nil, err
 	}
 	return tw.file, nil
 }
 
+// WriteTemplate writes an import template for T to writer: the headers with required ones marked
+// and a note describing every column, and the instructions sheet of the options listing the columns
+func WriteTemplate[T any](writer io.Writer, opts ...Option) error {
+	//Copy before appending, so the caller's slice is never written to
+	opts = append(opts[:len(opts):len(opts)], WithKeepEmptyColumns(true))
+	tw, err := NewTypeWriter[T](opts...)
+	if err != nil {
+		return err
+	}
+	if err = tw.writeTemplate(); err != nil {
+		return err
+	}
+	_, err = tw.WriteTo(writer)
+	return err
+}
+
 // NewTypeWriter creates a new TypeWriter[T] instance
 // It returns an error if the type T cannot be written to excel
 // This function is heavier, so it is recommended to create a single instance and reuse it
 // Otherwise, it is recommended to make it parallel while you fetch data to writeSingle
-func NewTypeWriter[T any](opts ...Options) (w *TypeWriter[T], err error) {
+func NewTypeWriter[T any](opts ...Option) (w *TypeWriter[T], err error) {
 	//panic recover
 	defer func() {
 		if r := recover(); r != nil {
@@ -89,21 +140,38 @@ func NewTypeWriter[T any](opts ...Options) (w *TypeWriter[T], err error) {
 			err = fmt.Errorf("panic: %v", r)
 		}
 	}()
-	w = &TypeWriter[T]{}
-	if err := w.analyzeType(); err != nil {
+	options, err := newOptions(opts...)
+	if err != nil {
 		return nil, err
 	}
-	if len(opts) > 0 {
-		w.options = &opts[0]
-	} else {
-		w.options = DefaultOptions()
+	w = &TypeWriter[T]{options: options}
+	if err := w.analyzeType(); err != nil {
+		return nil, err
 	}
 	if w.options.File != nil {
 		w.file = w.options.File
+		if w.options.Sheet != "" {
+			if err := w.file.SetDefaultSheet(w.options.Sheet); err != nil {
+				return nil, err
+			}
+		}
 	} else {
-		w.file = NewExcelizeWriter()
+		w.file = newExcelFile(w.options.Sheet)
+	}
+	if w.options.Password != "" {
+		w.file.SetPassword(w.options.Password)
 	}
+	w.sheet = w.file.GetDefaultSheet()
 	w.nextRowToWrite = w.options.HeaderRow
+	w.firstDataRow = w.options.DataStartRow
+	if w.options.Append {
+		if err := w.prepareAppend(); err != nil {
+			return nil, err
+		}
+	}
+	if w.child != nil && w.child.name == w.sheet {
+		return nil, fmt.Errorf("child sheet %s must differ from the sheet of T", w.child.name)
+	}
 	return w, nil
 }
 
@@ -114,7 +182,13 @@ func (w *TypeWriter[T]) Write(data []T) (err error) {
 			err = fmt.Errorf("panic: %v", r)
 		}
 	}()
+	if err := w.file.SetDefaultSheet(w.sheet); err != nil {
+		return err
+	}
 	if len(data) == 0 {
+		if w.nextRowToWrite > w.options.HeaderRow {
+			return nil //Headers are already written
+		}
 		return w.writeHeaders() //Write headers only
 	}
 	if w.nextRowToWrite == w.options.HeaderRow {
@@ -132,21 +206,367 @@ func (w *TypeWriter[T]) Write(data []T) (err error) {
 }
 
 func (w *TypeWriter[T]) removeEmptyColumns() {
+	w.visibleColumns = make([]int, 0, len(w.headers))
 	for i := len(w.headers) - 1; i >= 0; i-- {
 		fi := w.typeInfo.nameToField[w.headers[i]]
-		if !w.columnContainsValues[i] && (fi.omitEmpty || len(fi.index) > 1) {
-			name, err := excelize.ColumnNumberToName(i + 1)
-			if err != nil {
+		//Slice element columns are written to the child sheet instead
+		childColumn := w.child != nil && !w.parentColumns[i]
+		if childColumn || (!w.options.KeepEmptyColumns && i < len(w.columnContainsValues) && !w.columnContainsValues[i] && (fi.omitEmpty || len(fi.index) > 1)) {
+			name, err := excelize.ColumnNumberToName(w.headerColumn(i))
+			if err == nil && w.file.RemoveColumn(name) == nil {
 				continue
 			}
-			_ = w.file.RemoveColumn(name)
 		}
+		w.visibleColumns = append(w.visibleColumns, i)
+	}
+	//Collected from the right, reverse into column order
+	for i, j := 0, len(w.visibleColumns)-1; i < j; i, j = i+1, j-1 {
+		w.visibleColumns[i], w.visibleColumns[j] = w.visibleColumns[j], w.visibleColumns[i]
 	}
 }
 
-func (w *TypeWriter[T]) WriteToFile(filename string) error {
-	//Remove empty columns
+// finalize closes the open subtotal group and finalizes the sheet and the child sheet, it runs once before the file is saved
+func (w *TypeWriter[T]) finalize() error {
+	if w.finalized {
+		return nil
+	}
+	if err := w.file.SetDefaultSheet(w.sheet); err != nil {
+		return err
+	}
+	if err := w.closeGroup(); err != nil {
+		return err
+	}
+	if err := w.finalizeSheet(); err != nil {
+		return err
+	}
+	return w.finalizeChildSheet()
+}
+
+// finalizeSheet removes empty columns and formats the current sheet, leaving an open subtotal group open
+func (w *TypeWriter[T]) finalizeSheet() error {
+	w.finalized = true
 	w.removeEmptyColumns()
+	if err := w.applyStyles(); err != nil {
+		return err
+	}
+	if err := w.styleSubtotals(); err != nil {
+		return err
+	}
+	if err := w.addTotals(); err != nil {
+		return err
+	}
+	if err := w.applyLayout(); err != nil {
+		return err
+	}
+	if err := w.addDataValidations(); err != nil {
+		return err
+	}
+	if err := w.addTable(); err != nil {
+		return err
+	}
+	return w.addDefinedName()
+}
+
+// applyStyles styles the header with the header style of the options, and the data cells and widths of the columns with their tags
+func (w *TypeWriter[T]) applyStyles() error {
+	if w.columnContainsValues == nil {
+		return nil
+	}
+	if w.options.HeaderStyle != nil && len(w.visibleColumns) > 0 {
+		style, err := w.file.NewStyle(w.options.HeaderStyle)
+		if err != nil {
+			return fmt.Errorf("error creating header style: %w", err)
+		}
+		start, _ := excelize.CoordinatesToCellName(w.options.startColumn(), int(w.options.HeaderRow))
+		end, _ := excelize.CoordinatesToCellName(w.options.startColumn()+w.columnCount()-1, int(w.options.HeaderRow))
+		if err := w.file.SetCellStyle(start, end, style); err != nil {
+			return err
+		}
+	}
+	if w.template {
+		if err := w.markRequiredHeaders(); err != nil {
+			return err
+		}
+	}
+	for k, i := range w.visibleColumns {
+		fi := w.typeInfo.nameToField[w.headers[i]]
+		column, err := excelize.ColumnNumberToName(w.columnNumber(k))
+		if err != nil {
+			return err
+		}
+		if fi.width > 0 {
+			if err := w.file.SetColWidth(column, column, fi.width); err != nil {
+				return err
+			}
+		}
+		style, ok := columnStyle(fi, w.options)
+		if !ok || w.nextRowToWrite <= w.firstDataRow {
+			continue
+		}
+		styleID, err := w.file.NewStyle(style)
+		if err != nil {
+			return fmt.Errorf("error creating style of column %s: %w", fi.name, err)
+		}
+		start := column + strconv.Itoa(int(w.firstDataRow))
+		end := column + strconv.Itoa(int(w.nextRowToWrite-1))
+		if err := w.file.SetCellStyle(start, end, styleID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyLayout sizes the columns, freezes the header and turns on filtering as set in the options
+func (w *TypeWriter[T]) applyLayout() error {
+	if w.columnContainsValues == nil {
+		return nil
+	}
+	if w.options.AutoFitColumns {
+		for k, i := range w.visibleColumns {
+			if w.typeInfo.nameToField[w.headers[i]].width > 0 {
+				continue
+			}
+			column, err := excelize.ColumnNumberToName(w.columnNumber(k))
+			if err != nil {
+				return err
+			}
+			width := math.Min(float64(w.columnLengths[i])+2, excelize.MaxColumnWidth)
+			if err := w.file.SetColWidth(column, column, width); err != nil {
+				return err
+			}
+		}
+	}
+	if w.options.FreezeHeader {
+		topLeft, err := excelize.CoordinatesToCellName(1, int(w.options.DataStartRow))
+		if err != nil {
+			return err
+		}
+		if err := w.file.SetPanes(&excelize.Panes{
+			Freeze:      true,
+			YSplit:      int(w.options.DataStartRow) - 1,
+			TopLeftCell: topLeft,
+			ActivePane:  "bottomLeft",
+		}); err != nil {
+			return err
+		}
+	}
+	if w.options.OutlineSlices && w.parentColumns != nil {
+		//Summary rows are written above their group
+		summaryBelow := false
+		if err := w.file.SetSheetProps(&excelize.SheetPropsOptions{OutlineSummaryBelow: &summaryBelow}); err != nil {
+			return err
+		}
+	}
+	if w.options.AutoFilter && w.options.Table == "" {
+		ref, ok, err := w.writtenRange()
+		if err != nil || !ok {
+			return err
+		}
+		if err := w.file.AutoFilter(strings.ReplaceAll(ref, "$", "")); err != nil {
+			return fmt.Errorf("error adding autofilter: %w", err)
+		}
+	}
+	return nil
+}
+
+// addDataValidations adds the validations of the columns if the options enable them
+func (w *TypeWriter[T]) addDataValidations() error {
+	if !w.options.DataValidation || w.columnContainsValues == nil {
+		return nil
+	}
+	lastRow := w.options.DataStartRow + w.options.DataValidationRows - 1
+	if w.nextRowToWrite-1 > lastRow {
+		lastRow = w.nextRowToWrite - 1
+	}
+	if lastRow < w.options.DataStartRow {
+		return nil
+	}
+	for k, i := range w.visibleColumns {
+		fi := w.typeInfo.nameToField[w.headers[i]]
+		if fi.validation == nil {
+			continue
+		}
+		column, err := excelize.ColumnNumberToName(w.columnNumber(k))
+		if err != nil {
+			return err
+		}
+		dv, err := newDataValidation(*fi.validation)
+		if err != nil {
+			return fmt.Errorf("error creating validation of column %s: %w", fi.name, err)
+		}
+		dv.SetSqref(fmt.Sprintf("%s%d:%s%d", column, w.options.DataStartRow, column, lastRow))
+		if err := w.file.AddDataValidation(dv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newDataValidation creates the excelize validation of v, with an error message describing the allowed values
+func newDataValidation(v columnValidation) (*excelize.DataValidation, error) {
+	dv := excelize.NewDataValidation(true)
+	message := v.errorMsg
+	if len(v.oneOf) > 0 {
+		if err := dv.SetDropList(v.oneOf); err != nil {
+			return nil, err
+		}
+		if message == "" {
+			message = "Choose one of: " + strings.Join(v.oneOf, ", ")
+		}
+	} else {
+		operator := excelize.DataValidationOperatorBetween
+		switch {
+		case v.maximum == "":
+			operator = excelize.DataValidationOperatorGreaterThanOrEqual
+		case v.minimum == "":
+			operator = excelize.DataValidationOperatorLessThanOrEqual
+		}
+		first, second := v.minimum, v.maximum
+		if first == "" {
+			first, second = second, ""
+		}
+		if err := dv.SetRange(first, second, v.rangeType, operator); err != nil {
+			return nil, err
+		}
+		if message == "" {
+			message = "Enter a value " + v.bounds
+		}
+	}
+	dv.SetError(excelize.DataValidationErrorStyleStop, "Invalid value", message)
+	return dv, nil
+}
+
+// trackLength records the displayed length of value written to the header at index i
+func (w *TypeWriter[T]) trackLength(i int, value any) {
+	if length, ok := w.valueLength(i, value); ok && length > w.columnLengths[i] {
+		w.columnLengths[i] = length
+	}
+}
+
+// valueLength returns the displayed length of a value of the header at index i, ok is false if it is not known
+func (w *TypeWriter[T]) valueLength(i int, value any) (length int, ok bool) {
+	//Measure the displayed value, of what pointers point to and of custom types such as Hyperlink
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return 0, false
+		}
+		value = v.Elem().Interface()
+	}
+	if valuer, isValuer := value.(GexValuer); isValuer {
+		value = valuer.GexelizerValue()
+	}
+	switch v := value.(type) {
+	case nil, Formula:
+		//Their displayed value is not known
+		return 0, false
+	case string:
+		length = utf8.RuneCountInString(v)
+	case time.Time:
+		//Dates are displayed with the format of their column
+		length = utf8.RuneCountInString(columnNumFmt(w.typeInfo.nameToField[w.headers[i]], w.options))
+		if length == 0 {
+			length = len("2006-01-02 15:04:05")
+		}
+	default:
+		length = utf8.RuneCountInString(fmt.Sprint(v))
+	}
+	return length, true
+}
+
+// columnStyle returns the style of the data cells of a column from the tags of its field, ok is false if it has none.
+// Date columns without a numfmt tag get the date format of the options
+func columnStyle(fi fieldInfo, options Options) (style *excelize.Style, ok bool) {
+	numFmt := columnNumFmt(fi, options)
+	if numFmt == "" && fi.align == "" && !fi.wrap {
+		return nil, false
+	}
+	style = &excelize.Style{}
+	if id, err := strconv.Atoi(numFmt); err == nil {
+		style.NumFmt = id
+	} else if numFmt != "" {
+		style.CustomNumFmt = &numFmt
+	}
+	if fi.align != "" || fi.wrap {
+		style.Alignment = &excelize.Alignment{Horizontal: fi.align, WrapText: fi.wrap}
+	}
+	if fi.hyperlink {
+		//Keep the look of hyperlinks, the column style replaces theirs
+		style.Font = hyperlinkFont()
+	}
+	return style, true
+}
+
+// columnNumFmt returns the number format of a column, "" if it has none
+func columnNumFmt(fi fieldInfo, options Options) string {
+	switch {
+	case fi.numFmt != "":
+		return fi.numFmt
+	case fi.date:
+		return options.DateFormat
+	case fi.dateTime:
+		return options.DateTimeFormat
+	}
+	return ""
+}
+
+// writtenRange returns the absolute range of the written header and rows, ok is false if nothing was written
+func (w *TypeWriter[T]) writtenRange() (ref string, ok bool, err error) {
+	if w.columnContainsValues == nil || len(w.visibleColumns) == 0 {
+		return "", false, nil
+	}
+	lastRow := w.nextRowToWrite - 1
+	if lastRow < w.options.HeaderRow {
+		lastRow = w.options.HeaderRow
+	}
+	start, err := excelize.CoordinatesToCellName(w.options.startColumn(), int(w.options.HeaderRow), true)
+	if err != nil {
+		return "", false, err
+	}
+	end, err := excelize.CoordinatesToCellName(w.options.startColumn()+w.columnCount()-1, int(lastRow), true)
+	if err != nil {
+		return "", false, err
+	}
+	return start + ":" + end, true, nil
+}
+
+// addTable formats the written header and rows as an Excel Table if the options name one
+func (w *TypeWriter[T]) addTable() error {
+	if w.options.Table == "" {
+		return nil
+	}
+	ref, ok, err := w.writtenRange()
+	if err != nil || !ok {
+		return err
+	}
+	if err := w.file.AddTable(&excelize.Table{
+		Range:     strings.ReplaceAll(ref, "$", ""),
+		Name:      w.options.Table,
+		StyleName: w.options.TableStyle,
+	}); err != nil {
+		return fmt.Errorf("error adding table %s: %w", w.options.Table, err)
+	}
+	return nil
+}
+
+// addDefinedName defines the name of the options over the written header and rows
+func (w *TypeWriter[T]) addDefinedName() error {
+	if w.options.DefinedName == "" {
+		return nil
+	}
+	ref, ok, err := w.writtenRange()
+	if err != nil || !ok {
+		return err
+	}
+	if err := w.file.SetDefinedName(w.options.DefinedName, ref); err != nil {
+		return fmt.Errorf("error defining name %s: %w", w.options.DefinedName, err)
+	}
+	return nil
+}
+
+func (w *TypeWriter[T]) WriteToFile(filename string) error {
+	if err := w.finalize(); err != nil {
+		return err
+	}
 	//Create file
 	if err := w.file.SaveAs(filename); err != nil {
 		return err
@@ -155,24 +575,33 @@ func (w *TypeWriter[T]) WriteToFile(filename string) error {
 }
 
 func (w *TypeWriter[T]) WriteTo(writer io.Writer) (int64, error) {
-	//Remove empty columns
-	w.removeEmptyColumns()
+	if err := w.finalize(); err != nil {
+		return 0, err
+	}
 	return w.file.WriteTo(writer)
 }
 
 func (w *TypeWriter[T]) WriteToBuffer() (*bytes.Buffer, error) {
-	//Remove empty columns
-	w.removeEmptyColumns()
+	if err := w.finalize(); err != nil {
+		return nil, err
+	}
 	return w.file.WriteToBuffer()
 }
 
 func (w *TypeWriter[T]) analyzeType() error {
 	var t T
-	info, err := analyzeType(reflect.TypeOf(t))
+	info, err := cachedTypeInfo(reflect.TypeOf(t))
 	if err != nil {
 		return err
 	}
 	w.typeInfo = info
+	if generated, ok := any(t).(GexRowWriter); ok {
+		fields := generated.GexelizerFields()
+		if positions, ok := generatedFieldPositions(info, fields); ok {
+			w.fieldPositions = positions
+			w.generatedValues = make([]any, len(fields))
+		}
+	}
 	w.headers = make([]string, 0, len(info.orderedColumns))
 	//Include headers except for slices
 	for _, col := range info.orderedColumns {
@@ -182,17 +611,42 @@ func (w *TypeWriter[T]) analyzeType() error {
 		}
 		w.headers = append(w.headers, col)
 	}
-	return nil
+	if info.containsSlice() {
+		w.parentColumns = make([]bool, len(w.headers))
+		for i, col := range w.headers {
+			w.parentColumns[i] = !info.nameToField[col].isChildOf(*info.sliceFieldInfo)
+		}
+	}
+	for i, col := range w.headers {
+		if !info.nameToField[col].formula {
+			continue
+		}
+		if w.formulaColumns == nil {
+			w.formulaColumns = make([]bool, len(w.headers))
+		}
+		w.formulaColumns[i] = true
+	}
+	if err := w.analyzeChildSheet(); err != nil {
+		return err
+	}
+	if err := w.analyzeTotals(); err != nil {
+		return err
+	}
+	return w.analyzeSubtotals()
 }
 
 func (w *TypeWriter[T]) writeHeaders() error {
-	capitalized := make([]string, len(w.headers))
+	labels := make([]string, len(w.headers))
 	w.columnContainsValues = make([]bool, len(w.headers))
+	if len(w.columnLengths) == 0 {
+		w.columnLengths = make([]int, len(w.headers))
+	}
 	for i, header := range w.headers {
-		capitalized[i] = strings.ToUpper(header[0:1]) + header[1:]
+		labels[i] = w.options.headerLabel(header, w.typeInfo.nameToField[header])
 		w.columnContainsValues[i] = false
+		w.trackLength(i, labels[i])
 	}
-	return w.file.SetStringRow(w.options.HeaderRow, capitalized)
+	return w.file.SetStringRow(uint(w.options.startColumn()), w.options.HeaderRow, labels)
 }
 
 type singleWrite struct {
@@ -238,8 +692,22 @@ func (r *singleWrite) setColumnValue(x int, value any) {
 }
 
 func (w *TypeWriter[T]) writeSingle(row T) error {
+	rows, children := w.toRows(row)
+	return w.writeRows(rows, children)
+}
+
+// toRows returns the rows row is written as, one per slice element, and the number of slice elements
+func (w *TypeWriter[T]) toRows(row T) ([][]any, int) {
 	sw := newRows(len(w.headers))
-	if !w.typeInfo.containsSlice() {
+	children := 0
+	if w.fieldPositions != nil {
+		any(row).(GexRowWriter).GexelizerWriteRow(w.generatedValues)
+		for i, position := range w.fieldPositions {
+			if value := w.generatedValues[position]; value != nil {
+				sw.setColumnValue(i, value)
+			}
+		}
+	} else if !w.typeInfo.containsSlice() {
 		for i := 0; i < len(w.typeInfo.orderedColumns); i++ {
 			col := w.typeInfo.orderedColumns[i]
 			fi := w.typeInfo.nameToField[col]
@@ -253,6 +721,7 @@ func (w *TypeWriter[T]) writeSingle(row T) error {
 		passedSlice := false
 		sliceFI := *w.typeInfo.sliceFieldInfo
 		sliceFV := reflect.ValueOf(row).FieldByIndex(sliceFI.index)
+		children = sliceFV.Len()
 		for i := 0; i < len(w.typeInfo.orderedColumns); i++ {
 			col := w.typeInfo.orderedColumns[i]
 			fi := w.typeInfo.nameToField[col]
@@ -287,13 +756,62 @@ func (w *TypeWriter[T]) writeSingle(row T) error {
 			sw.setColumnValue(x, fv.Interface())
 		}
 	}
+	return sw.rows, children
+}
+
+// writeRows writes the rows of a single value of T, children being its number of slice elements
+func (w *TypeWriter[T]) writeRows(rows [][]any, children int) error {
 	if len(w.columnContainsValues) == 0 {
 		w.columnContainsValues = make([]bool, len(w.headers))
 	}
-	for _, row := range sw.rows {
-		if err := w.file.SetRow(w.nextRowToWrite, row); err != nil {
+	if len(w.columnLengths) == 0 {
+		w.columnLengths = make([]int, len(w.headers))
+	}
+	if w.child != nil {
+		if err := w.writeChildRows(rows, children); err != nil {
+			return err
+		}
+		rows, children = [][]any{w.summaryRow(rows[0])}, 0
+	}
+	first := rows[0]
+	outlined := w.options.OutlineSlices && children > 0
+	if outlined {
+		rows = append([][]any{w.summaryRow(first)}, rows...)
+	}
+	//The subtotal row of the previous group is written first, in the room kept for it
+	if err := w.startGroup(first); err != nil {
+		return err
+	}
+	if err := w.makeRoom(len(rows)); err != nil {
+		return err
+	}
+	firstRow := w.nextRowToWrite
+	for k, row := range rows {
+		if k > 0 && w.options.ParentCells != ParentCellsRepeat {
+			w.clearParentCells(row)
+		}
+		w.toFormulas(row)
+		w.toLocation(row)
+		if err := w.file.SetRow(uint(w.options.startColumn()), w.nextRowToWrite, w.sheetRow(row)); err != nil {
 			return err
 		}
+		level := uint8(0)
+		if w.subtotals != nil && w.options.SubtotalOutline {
+			level++
+		}
+		if outlined && k > 0 {
+			level++
+		}
+		if level > 0 {
+			if err := w.file.SetRowOutlineLevel(w.nextRowToWrite, level); err != nil {
+				return err
+			}
+		}
+		if w.options.ImageRowHeight > 0 && containsImage(row) {
+			if err := w.file.SetRowHeight(w.nextRowToWrite, w.options.ImageRowHeight); err != nil {
+				return err
+			}
+		}
 		for i, value := range row {
 			if value == nil {
 				continue
@@ -301,8 +819,214 @@ func (w *TypeWriter[T]) writeSingle(row T) error {
 			if !reflect.ValueOf(value).IsZero() {
 				w.columnContainsValues[i] = true
 			}
+			w.trackLength(i, value)
 		}
 		w.nextRowToWrite++
 	}
+	if w.options.ParentCells == ParentCellsMerge && len(rows) > 1 {
+		return w.mergeParentCells(firstRow, w.nextRowToWrite-1)
+	}
+	return nil
+}
+
+// summaryRow returns the parent values of row, written above the rows of the slice elements when outlining
+func (w *TypeWriter[T]) summaryRow(row []any) []any {
+	summary := make([]any, len(row))
+	for i, isParent := range w.parentColumns {
+		if isParent {
+			summary[i] = row[i]
+		}
+	}
+	return summary
+}
+
+// clearParentCells removes the values of parent columns from a row continuing the previous one
+func (w *TypeWriter[T]) clearParentCells(row []any) {
+	for i, isParent := range w.parentColumns {
+		if isParent {
+			row[i] = nil
+		}
+	}
+}
+
+// mergeParentCells merges the cells of every parent column from firstRow to lastRow
+func (w *TypeWriter[T]) mergeParentCells(firstRow, lastRow uint) error {
+	for i, isParent := range w.parentColumns {
+		if !isParent {
+			continue
+		}
+		start, err := excelize.CoordinatesToCellName(w.headerColumn(i), int(firstRow))
+		if err != nil {
+			return err
+		}
+		end, err := excelize.CoordinatesToCellName(w.headerColumn(i), int(lastRow))
+		if err != nil {
+			return err
+		}
+		if err := w.file.MergeCell(start, end); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toFormulas converts the string values of formula columns starting with "=" into Formula values
+func (w *TypeWriter[T]) toFormulas(row []any) {
+	for i, isFormula := range w.formulaColumns {
+		if !isFormula || row[i] == nil {
+			continue
+		}
+		if v := reflect.ValueOf(row[i]); v.Kind() == reflect.String && strings.HasPrefix(v.String(), "=") {
+			row[i] = Formula(v.String())
+		}
+	}
+}
+
+// toLocation converts the time.Time values of row to the location of the options, as Excel dates hold the wall clock only
+func (w *TypeWriter[T]) toLocation(row []any) {
+	for i, value := range row {
+		switch t := value.(type) {
+		case time.Time:
+			row[i] = t.In(w.options.location())
+		case *time.Time:
+			if t != nil {
+				row[i] = t.In(w.options.location())
+			}
+		}
+	}
+}
+
+func containsImage(row []any) bool {
+	for _, value := range row {
+		switch image := value.(type) {
+		case Image:
+			if len(image.Data) > 0 {
+				return true
+			}
+		case *Image:
+			if image != nil && len(image.Data) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// templateColumn is a row of the instructions sheet of templates
+type templateColumn struct {
+	Column        string `gex:"column:column"`
+	Required      string `gex:"column:required"`
+	Type          string `gex:"column:type"`
+	Format        string `gex:"column:format,omitempty"`
+	AllowedValues string `gex:"column:allowed values,omitempty"`
+	Aliases       string `gex:"column:aliases,omitempty"`
+	Default       string `gex:"column:default,omitempty"`
+	Description   string `gex:"column:description,omitempty"`
+}
+
+// writeTemplate writes the headers with a note on every column and the instructions sheet
+func (w *TypeWriter[T]) writeTemplate() error {
+	w.template = true
+	if err := w.file.SetDefaultSheet(w.sheet); err != nil {
+		return err
+	}
+	if err := w.writeHeaders(); err != nil {
+		return err
+	}
+	columns := make([]templateColumn, len(w.headers))
+	for i, header := range w.headers {
+		fi := w.typeInfo.nameToField[header]
+		columns[i] = w.templateColumn(header, fi)
+		cell, err := excelize.CoordinatesToCellName(w.headerColumn(i), int(w.options.HeaderRow))
+		if err != nil {
+			return err
+		}
+		if err := w.file.AddComment(cell, columns[i].note()); err != nil {
+			return err
+		}
+	}
+	if w.options.InstructionsSheet == "" || strings.EqualFold(w.options.InstructionsSheet, w.sheet) {
+		return fmt.Errorf("instructions sheet %q has to differ from sheet %s", w.options.InstructionsSheet, w.sheet)
+	}
+	_, err := WriteExcelSheet(w.file, w.options.InstructionsSheet, columns, WithRemoveEmptyColumns(true), WithAutoFitColumns(true), WithFreezeHeader(true),
+		WithHeaderStyle(&excelize.Style{Font: &excelize.Font{Bold: true}}))
+	return err
+}
+
+func (w *TypeWriter[T]) templateColumn(header string, fi fieldInfo) templateColumn {
+	column := templateColumn{
+		Column:      w.options.headerLabel(header, fi),
+		Required:    "no",
+		Type:        fi.valueType,
+		Format:      columnNumFmt(fi, w.options),
+		Default:     fi.defaultValue,
+		Description: fi.description,
+	}
+	if fi.required || fi.isPrimaryKey {
+		column.Required = "yes"
+	}
+	if v := fi.validation; v != nil {
+		if len(v.oneOf) > 0 {
+			column.AllowedValues = strings.Join(v.oneOf, ", ")
+		} else {
+			column.AllowedValues = v.bounds
+		}
+	}
+	column.Aliases = strings.Join(fi.aliases, ", ")
+	return column
+}
+
+// note returns the text of the note on the header of the column
+func (c templateColumn) note() string {
+	lines := make([]string, 0, 6)
+	if c.Description != "" {
+		lines = append(lines, c.Description)
+	}
+	lines = append(lines, "Type: "+c.Type)
+	if c.Format != "" {
+		lines = append(lines, "Format: "+c.Format)
+	}
+	if c.AllowedValues != "" {
+		lines = append(lines, "Allowed values: "+c.AllowedValues)
+	}
+	if c.Default != "" {
+		lines = append(lines, "Default: "+c.Default)
+	}
+	if c.Required == "yes" {
+		lines = append(lines, "Required")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markRequiredHeaders styles the headers of required columns, on top of the header style of the options
+func (w *TypeWriter[T]) markRequiredHeaders() error {
+	style := excelize.Style{}
+	if w.options.HeaderStyle != nil {
+		style = *w.options.HeaderStyle
+	}
+	font := excelize.Font{}
+	if style.Font != nil {
+		font = *style.Font
+	}
+	font.Bold = true
+	font.Color = "C00000"
+	style.Font = &font
+	styleID, err := w.file.NewStyle(&style)
+	if err != nil {
+		return err
+	}
+	for k, i := range w.visibleColumns {
+		fi := w.typeInfo.nameToField[w.headers[i]]
+		if !fi.required && !fi.isPrimaryKey {
+			continue
+		}
+		cell, err := excelize.CoordinatesToCellName(w.columnNumber(k), int(w.options.HeaderRow))
+		if err != nil {
+			return err
+		}
+		if err := w.file.SetCellStyle(cell, cell, styleID); err != nil {
+			return err
+		}
+	}
 	return nil
 }
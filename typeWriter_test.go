@@ -1,7 +1,9 @@
 package gexelizer
 
 import (
+	"bytes"
 	"github.com/xuri/excelize/v2"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -429,3 +431,410 @@ func TestTypeWriter_WriteBufferNilOmitempty(t *testing.T) {
 		t.Fatal("Should be empty")
 	}
 }
+
+func TestTypeWriter_Styles(t *testing.T) {
+	type row struct {
+		Name   string  `gex:"column:name,width:30,wrap"`
+		Amount float64 `gex:"column:amount,numfmt:4,align:right"`
+		Rate   float64 `gex:"column:rate,numfmt:0.00%"`
+		Note   string  `gex:"column:note,omitempty,width:12"`
+	}
+	data := []row{{Name: "John", Amount: 1234.5, Rate: 0.25}, {Name: "Jane", Amount: 10, Rate: 0.5}}
+	headerStyle := &excelize.Style{
+		Font:   &excelize.Font{Bold: true},
+		Fill:   excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"DDEBF7"}},
+		Border: []excelize.Border{{Type: "bottom", Color: "000000", Style: 1}},
+	}
+	buffer, err := WriteExcelToBuffer(data, WithStartCell("B2"), WithHeaderStyle(headerStyle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	styleOf := func(cell string) *excelize.Style {
+		id, err := file.GetCellStyle("Sheet1", cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		style, err := file.GetStyle(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return style
+	}
+	for _, cell := range []string{"B2", "D2"} {
+		if style := styleOf(cell); style.Font == nil || !style.Font.Bold || style.Fill.Pattern != 1 {
+			t.Fatalf("expected the header style at %s, got %+v", cell, style)
+		}
+	}
+	if style := styleOf("E2"); style.Font != nil && style.Font.Bold {
+		t.Fatal("expected the header style to stop at the last column left after removing empty columns")
+	}
+	if style := styleOf("B4"); style.Alignment == nil || !style.Alignment.WrapText {
+		t.Fatalf("expected wrapped names, got %+v", style)
+	}
+	if style := styleOf("C3"); style.NumFmt != 4 || style.Alignment == nil || style.Alignment.Horizontal != "right" {
+		t.Fatalf("expected a right aligned number format, got %+v", style)
+	}
+	if style := styleOf("D4"); style.CustomNumFmt == nil || *style.CustomNumFmt != "0.00%" {
+		t.Fatalf("expected a custom number format, got %+v", style)
+	}
+	if width, _ := file.GetColWidth("Sheet1", "B"); width != 30 {
+		t.Fatalf("expected the name column to be 30 wide, got %v", width)
+	}
+	if value, _ := file.GetCellValue("Sheet1", "C3"); value != "1,234.50" {
+		t.Fatalf("expected the formatted amount, got %q", value)
+	}
+
+	type invalid struct {
+		Name string `gex:"width:wide"`
+	}
+	if _, err := NewTypeWriter[invalid](); err == nil {
+		t.Fatal("expected an invalid width to be rejected")
+	}
+	type invalidAlign struct {
+		Name string `gex:"align:middle"`
+	}
+	if _, err := NewTypeWriter[invalidAlign](); err == nil {
+		t.Fatal("expected an invalid alignment to be rejected")
+	}
+}
+
+func TestTypeWriter_Layout(t *testing.T) {
+	type row struct {
+		Name   string  `gex:"column:name"`
+		City   string  `gex:"column:city,width:8"`
+		Amount float64 `gex:"column:amount"`
+	}
+	data := []row{{Name: "John", City: "Tbilisi", Amount: 10}, {Name: "Alexandria Ocasio", City: "New York", Amount: 1234.5}}
+	buffer, err := WriteExcelToBuffer(data, WithHeaderRow(2), WithAutoFitColumns(true), WithFreezeHeader(true), WithAutoFilter(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if width, _ := file.GetColWidth("Sheet1", "A"); width != float64(len("Alexandria Ocasio")+2) {
+		t.Fatalf("expected the name column to fit the longest name, got %v", width)
+	}
+	if width, _ := file.GetColWidth("Sheet1", "B"); width != 8 {
+		t.Fatalf("expected the width tag to win over auto fit, got %v", width)
+	}
+	if width, _ := file.GetColWidth("Sheet1", "C"); width != float64(len("Amount")+2) {
+		t.Fatalf("expected the amount column to fit its header, got %v", width)
+	}
+	panes, err := file.GetPanes("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !panes.Freeze || panes.YSplit != 2 || panes.TopLeftCell != "A3" {
+		t.Fatalf("expected the rows above the data to be frozen, got %+v", panes)
+	}
+	names := file.GetDefinedName()
+	if len(names) != 1 || names[0].Name != "_xlnm._FilterDatabase" || names[0].RefersTo != "'Sheet1'!$A$2:$C$4" {
+		t.Fatalf("expected an autofilter over the written range, got %+v", names)
+	}
+}
+
+func TestTypeWriter_AutoFitDisplayedValues(t *testing.T) {
+	type row struct {
+		Count *int      `gex:"column:a"`
+		Note  *string   `gex:"column:b"`
+		Link  Hyperlink `gex:"column:c"`
+	}
+	count, note := 1, "x"
+	data := []row{{Count: &count, Note: &note, Link: Hyperlink{URL: "https://example.com/a/long/path", Text: "go"}}}
+	buffer, err := WriteExcelToBuffer(data, WithAutoFitColumns(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for column, expected := range map[string]float64{"A": 3, "B": 3, "C": 4} {
+		if width, _ := file.GetColWidth("Sheet1", column); width != expected {
+			t.Fatalf("expected column %s to fit its displayed value with width %v, got %v", column, expected, width)
+		}
+	}
+}
+
+func TestTypeWriter_DataValidation(t *testing.T) {
+	type row struct {
+		Name     string    `gex:"column:name"`
+		Status   string    `gex:"column:status,oneof:new|active|closed,errormsg:Pick a status from the list"`
+		Quantity int       `gex:"column:quantity,min:1,max:100"`
+		Price    float64   `gex:"column:price,min:0.5"`
+		Due      time.Time `gex:"column:due,max:2030-12-31"`
+		Active   bool      `gex:"column:active"`
+	}
+	data := []row{{Name: "John", Status: "new", Quantity: 2, Price: 1, Due: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Active: true}}
+	buffer, err := WriteExcelToBuffer(data, WithDataValidation(50))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validations, err := file.GetDataValidations("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bySqref := make(map[string]*excelize.DataValidation)
+	for _, dv := range validations {
+		bySqref[dv.Sqref] = dv
+	}
+	if len(bySqref) != 5 {
+		t.Fatalf("expected 5 validations, got %d", len(bySqref))
+	}
+	if dv := bySqref["B2:B51"]; dv == nil || dv.Type != "list" || dv.Formula1 != `"new,active,closed"` || *dv.Error != "Pick a status from the list" {
+		t.Fatalf("unexpected status validation %+v", dv)
+	}
+	if dv := bySqref["C2:C51"]; dv == nil || dv.Type != "whole" || dv.Operator != "between" || dv.Formula1 != "1" || dv.Formula2 != "100" {
+		t.Fatalf("unexpected quantity validation %+v", dv)
+	}
+	if dv := bySqref["D2:D51"]; dv == nil || dv.Type != "decimal" || dv.Operator != "greaterThanOrEqual" || *dv.Error != "Enter a value of at least 0.5" {
+		t.Fatalf("unexpected price validation %+v", dv)
+	}
+	if dv := bySqref["E2:E51"]; dv == nil || dv.Type != "date" || dv.Operator != "lessThanOrEqual" || dv.Formula1 != "47848" {
+		t.Fatalf("unexpected due validation %+v", dv)
+	}
+	if dv := bySqref["F2:F51"]; dv == nil || dv.Formula1 != `"TRUE,FALSE"` {
+		t.Fatalf("unexpected active validation %+v", dv)
+	}
+
+	plain, err := WriteExcelToBuffer(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plainFile, err := excelize.OpenReader(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if validations, _ := plainFile.GetDataValidations("Sheet1"); len(validations) != 0 {
+		t.Fatal("expected no validations without the option")
+	}
+	type invalid struct {
+		Name string `gex:"min:1"`
+	}
+	if _, err := NewTypeWriter[invalid](); err == nil {
+		t.Fatal("expected min on a string field to be rejected")
+	}
+}
+
+func TestWriteTemplate(t *testing.T) {
+	type address struct {
+		City string `gex:"column:city,desc:'City of delivery, or of pickup'"`
+	}
+	type row struct {
+		SKU      string   `gex:"column:sku,primary,desc:Product code"`
+		Name     string   `gex:"column:name,required,aliases:title|product"`
+		Status   string   `gex:"column:status,oneof:new|active,default:new"`
+		Quantity int      `gex:"column:quantity,min:1,omitempty"`
+		Due      Date     `gex:"column:due"`
+		Address  *address `gex:"omitempty"`
+	}
+	buffer := &bytes.Buffer{}
+	if err := WriteTemplate[row](buffer, WithHeaderStyle(&excelize.Style{Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"DDEBF7"}}})); err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers, err := file.GetRows("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedHeaders := []string{"Sku", "Name", "Status", "Quantity", "Due", "Address.city"}
+	if len(headers) != 1 || !reflect.DeepEqual(headers[0], expectedHeaders) {
+		t.Fatalf("expected only the headers %v, got %v", expectedHeaders, headers)
+	}
+	comments, err := file.GetComments("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	notes := make(map[string]string)
+	for _, comment := range comments {
+		notes[comment.Cell] = comment.Text
+	}
+	if len(notes) != len(expectedHeaders) {
+		t.Fatalf("expected a note on every header, got %v", notes)
+	}
+	if notes["A1"] != "Product code\nType: text\nRequired" || notes["C1"] != "Type: text\nAllowed values: new, active\nDefault: new" || notes["E1"] != "Type: date\nFormat: yyyy-mm-dd" {
+		t.Fatalf("unexpected notes %q", notes)
+	}
+	requiredStyle, _ := file.GetCellStyle("Sheet1", "B1")
+	optionalStyle, _ := file.GetCellStyle("Sheet1", "C1")
+	if style, _ := file.GetStyle(requiredStyle); style.Font == nil || !style.Font.Bold || style.Fill.Pattern != 1 || requiredStyle == optionalStyle {
+		t.Fatalf("expected required headers to be marked on top of the header style, got %+v", style)
+	}
+
+	instructions, err := ReadExcel[templateColumn](bytes.NewReader(buffer.Bytes()), WithSheet("Instructions"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instructions) != len(expectedHeaders) {
+		t.Fatalf("expected a row per column, got %+v", instructions)
+	}
+	expected := templateColumn{Column: "Name", Required: "yes", Type: "text", Aliases: "title, product"}
+	if instructions[1] != expected {
+		t.Fatalf("expected %+v, got %+v", expected, instructions[1])
+	}
+	if instructions[3].AllowedValues != "of at least 1" || instructions[5].Description != "City of delivery, or of pickup" {
+		t.Fatalf("unexpected instructions %+v", instructions)
+	}
+	buffer.Reset()
+	if err := WriteTemplate[row](buffer, WithInstructionsSheet("Help")); err != nil {
+		t.Fatal(err)
+	}
+	if instructions, err := ReadExcel[templateColumn](bytes.NewReader(buffer.Bytes()), WithSheet("Help")); err != nil || len(instructions) != len(expectedHeaders) {
+		t.Fatalf("expected the columns on the Help sheet, got %+v (%v)", instructions, err)
+	}
+	if err := WriteTemplate[row](&bytes.Buffer{}, WithInstructionsSheet("Sheet1")); err == nil {
+		t.Fatal("expected the instructions sheet to differ from the template sheet")
+	}
+}
+
+func TestTypeWriter_ParentCells(t *testing.T) {
+	type job struct {
+		Position string `gex:"column:position"`
+	}
+	type row struct {
+		Name string `gex:"column:name,primary"`
+		Age  int    `gex:"column:age"`
+		Jobs []job
+	}
+	data := []row{
+		{Name: "John", Age: 20, Jobs: []job{{"A"}, {"B"}, {"C"}}},
+		{Name: "Jane", Age: 21, Jobs: []job{{"D"}}},
+		{Name: "Jack", Age: 22, Jobs: []job{{"E"}, {"F"}}},
+	}
+	for _, mode := range []ParentCellMode{ParentCellsRepeat, ParentCellsMerge, ParentCellsBlank} {
+		buffer, err := WriteExcelToBuffer(data, WithParentCells(mode))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows := sheetRows(t, buffer.Bytes())
+		if len(rows) != 7 || rows[1][0] != "John" || rows[4][0] != "Jane" || rows[5][2] != "E" {
+			t.Fatalf("mode %d: unexpected rows %v", mode, rows)
+		}
+		if continued := rows[2][0]; (mode == ParentCellsRepeat) != (continued == "John") {
+			t.Fatalf("mode %d: unexpected continuation row %v", mode, rows[2])
+		}
+		file, err := excelize.OpenReader(bytes.NewReader(buffer.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		merged, err := file.GetMergeCells("Sheet1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedMerges := 0
+		if mode == ParentCellsMerge {
+			//name and age over John's and Jack's rows, Jane has a single row
+			expectedMerges = 4
+		}
+		if len(merged) != expectedMerges {
+			t.Fatalf("mode %d: expected %d merged ranges, got %d", mode, expectedMerges, len(merged))
+		}
+
+		read, err := ReadExcel[row](bytes.NewReader(buffer.Bytes()), WithParentCells(mode))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(read, data) {
+			t.Fatalf("mode %d: expected %+v, got %+v", mode, data, read)
+		}
+		if _, err := ReadExcel[row](bytes.NewReader(buffer.Bytes())); (err == nil) != (mode == ParentCellsRepeat) {
+			t.Fatalf("mode %d: expected blank primary keys to fail without the parent cell mode, got %v", mode, err)
+		}
+	}
+	if _, err := newOptions(WithParentCells(ParentCellMode(7))); err == nil {
+		t.Fatal("expected an unknown parent cell mode to be rejected")
+	}
+}
+
+func TestTypeWriter_OutlineSlices(t *testing.T) {
+	type job struct {
+		Position string `gex:"column:position"`
+	}
+	type row struct {
+		Name string `gex:"column:name,primary"`
+		Age  int    `gex:"column:age"`
+		Jobs []job
+	}
+	data := []row{
+		{Name: "John", Age: 20, Jobs: []job{{"A"}, {"B"}}},
+		{Name: "Jane", Age: 21},
+		{Name: "Jack", Age: 22, Jobs: []job{{"C"}}},
+	}
+	for _, mode := range []ParentCellMode{ParentCellsRepeat, ParentCellsBlank} {
+		buffer, err := WriteExcelToBuffer(data, WithOutlineSlices(true), WithParentCells(mode))
+		if err != nil {
+			t.Fatal(err)
+		}
+		file, err := excelize.OpenReader(bytes.NewReader(buffer.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := file.GetRows("Sheet1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		//Header, John with two jobs, Jane without jobs and Jack with one job
+		if len(rows) != 7 || len(rows[1]) != 2 || rows[1][0] != "John" || rows[3][2] != "B" || rows[4][0] != "Jane" || rows[5][0] != "Jack" {
+			t.Fatalf("unexpected rows %v", rows)
+		}
+		expectedLevels := []uint8{0, 0, 1, 1, 0, 0, 1}
+		for i, expected := range expectedLevels {
+			level, err := file.GetRowOutlineLevel("Sheet1", i+1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if level != expected {
+				t.Fatalf("expected row %d at outline level %d, got %d", i+1, expected, level)
+			}
+		}
+		props, err := file.GetSheetProps("Sheet1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if props.OutlineSummaryBelow == nil || *props.OutlineSummaryBelow {
+			t.Fatal("expected summary rows above their group")
+		}
+
+		read, err := ReadExcel[row](bytes.NewReader(buffer.Bytes()), WithOutlineSlices(true), WithParentCells(mode))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(read, data) {
+			t.Fatalf("mode %d: expected %+v, got %+v", mode, data, read)
+		}
+	}
+}
+
+func TestExcelFile_SetRowLeavesValues(t *testing.T) {
+	file := &excelFile{file: excelize.NewFile()}
+	amount := 42
+	link := Hyperlink{URL: "https://example.com", Text: "Example"}
+	values := []any{&amount, (*string)(nil), link, &link}
+	expected := append([]any(nil), values...)
+	if err := file.SetRow(1, 1, values); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatalf("expected the values to be left as they are, got %v", values)
+	}
+	rows, err := file.GetSheetRows(file.GetDefaultSheet())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedRow := []string{"42", "", "Example", "Example"}; !reflect.DeepEqual(rows[0], expectedRow) {
+		t.Fatalf("expected %v, got %v", expectedRow, rows[0])
+	}
+}
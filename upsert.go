@@ -0,0 +1,142 @@
+package gexelizer
+
+import (
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"reflect"
+	"strings"
+)
+
+// UpsertExcel updates the rows of the sheet of file whose primary column matches a value of data, and appends the others.
+// Only the cells of columns mapped to T are written, other columns, formulas and styles are left as they are.
+// The sheet is chosen with WithSheet, and the file is saved by the caller
+func UpsertExcel[T any](file ExcelFileWriter, data []T, opts ...Option) (err error) {
+	//panic recover
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	if file == nil {
+		return fmt.Errorf("file cannot be nil")
+	}
+	//Copy before appending, so the caller's slice is never written to
+	opts = append(opts[:len(opts):len(opts)], WithFile(file), WithAppend(true))
+	w, err := NewTypeWriter[T](opts...)
+	if err != nil {
+		return err
+	}
+	if w.typeInfo.containsSlice() {
+		return fmt.Errorf("upserting types with a slice is not supported")
+	}
+	primaryIndex := -1
+	for i, header := range w.headers {
+		if w.typeInfo.nameToField[header].isPrimaryKey {
+			primaryIndex = i
+		}
+	}
+	if primaryIndex < 0 {
+		return fmt.Errorf("upserting requires a primary column")
+	}
+	if err := w.file.SetDefaultSheet(w.sheet); err != nil {
+		return err
+	}
+	if w.nextRowToWrite == w.options.HeaderRow {
+		w.nextRowToWrite = w.options.DataStartRow
+		if err := w.writeHeaders(); err != nil {
+			return err
+		}
+	}
+	keys, err := w.existingKeys(primaryIndex)
+	if err != nil {
+		return err
+	}
+	styles := make(map[int]int)
+	for _, value := range data {
+		rows, children := w.toRows(value)
+		key := upsertKey(rows[0][primaryIndex])
+		if rowNumber, exists := keys[key]; exists && key != "" {
+			if err := w.updateRow(rowNumber, rows[0], styles); err != nil {
+				return err
+			}
+			continue
+		}
+		keys[key] = w.nextRowToWrite
+		if err := w.writeRows(rows, children); err != nil {
+			return err
+		}
+	}
+	return w.finalize()
+}
+
+// existingKeys indexes the data rows of the sheet by the value of their primary column, the first row wins on duplicates
+func (w *TypeWriter[T]) existingKeys(primaryIndex int) (map[string]uint, error) {
+	keys := make(map[string]uint)
+	if w.firstDataRow <= w.options.DataStartRow {
+		return keys, nil
+	}
+	rows, err := w.file.GetSheetRows(w.sheet)
+	if err != nil {
+		return nil, err
+	}
+	column := w.headerColumn(primaryIndex) - 1
+	for rowNumber := w.options.DataStartRow; rowNumber < w.firstDataRow && int(rowNumber) <= len(rows); rowNumber++ {
+		row := rows[rowNumber-1]
+		if column >= len(row) {
+			continue
+		}
+		key := upsertKey(row[column])
+		if _, exists := keys[key]; key != "" && !exists {
+			keys[key] = rowNumber
+		}
+	}
+	return keys, nil
+}
+
+// updateRow writes the mapped cells of an existing row one by one, styling those without a style of their own.
+// Nil values and empty omitempty values leave their cells as they are
+func (w *TypeWriter[T]) updateRow(rowNumber uint, row []any, styles map[int]int) error {
+	w.toFormulas(row)
+	w.toLocation(row)
+	for i, value := range row {
+		fi := w.typeInfo.nameToField[w.headers[i]]
+		if v := reflect.ValueOf(value); !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) || (fi.omitEmpty && v.IsZero()) {
+			continue
+		}
+		column := w.headerColumn(i)
+		if err := w.file.SetRow(uint(column), rowNumber, []any{value}); err != nil {
+			return err
+		}
+		w.trackLength(i, value)
+		style, ok := columnStyle(fi, w.options)
+		if !ok {
+			continue
+		}
+		cell, err := excelize.CoordinatesToCellName(column, int(rowNumber))
+		if err != nil {
+			return err
+		}
+		if existing, err := w.file.GetCellStyle(cell); err != nil || existing != 0 {
+			continue
+		}
+		styleID, exists := styles[i]
+		if !exists {
+			if styleID, err = w.file.NewStyle(style); err != nil {
+				return fmt.Errorf("error creating style of column %s: %w", fi.name, err)
+			}
+			styles[i] = styleID
+		}
+		if err := w.file.SetCellStyle(cell, cell, styleID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upsertKey normalizes a primary column value the way the reader compares primary keys
+func upsertKey(value any) string {
+	if value == nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(fmt.Sprint(value)))
+}
@@ -0,0 +1,89 @@
+package gexelizer
+
+import (
+	"bytes"
+	"github.com/xuri/excelize/v2"
+	"reflect"
+	"testing"
+)
+
+func TestUpsertExcel(t *testing.T) {
+	type price struct {
+		SKU   string  `gex:"column:sku,primary"`
+		Name  string  `gex:"column:name"`
+		Price float64 `gex:"column:price"`
+		Notes *string `gex:"column:notes,omitempty"`
+	}
+	//A price list edited by hand, with a styled price, a formula column and notes
+	existing := excelize.NewFile()
+	_ = existing.SetSheetName("Sheet1", "Prices")
+	_ = existing.SetSheetRow("Prices", "A1", &[]any{"SKU", "Price", "Doubled", "Name", "Notes"})
+	_ = existing.SetSheetRow("Prices", "A2", &[]any{"a-1", 10, nil, "Apple", "seasonal"})
+	_ = existing.SetSheetRow("Prices", "A3", &[]any{"b-2", 20, nil, "Banana"})
+	_ = existing.SetCellFormula("Prices", "C2", "B2*2")
+	_ = existing.SetCellFormula("Prices", "C3", "B3*2")
+	highlight, err := existing.NewStyle(&excelize.Style{Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFFF00"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = existing.SetCellStyle("Prices", "B2", "B2", highlight)
+	buffer, err := existing.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := OpenExcelizeWriter(buffer, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh := "fresh"
+	data := []price{
+		{SKU: "A-1", Name: "Green apple", Price: 12},
+		{SKU: "c-3", Name: "Cherry", Price: 30, Notes: &fresh},
+	}
+	if err := UpsertExcel(file, data, WithSheet("Prices")); err != nil {
+		t.Fatal(err)
+	}
+	upserted, err := file.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := excelize.OpenReader(bytes.NewReader(upserted.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := result.GetRows("Prices")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]string{
+		{"SKU", "Price", "Doubled", "Name", "Notes"},
+		{"A-1", "12", "", "Green apple", "seasonal"},
+		{"b-2", "20", "", "Banana"},
+		{"c-3", "30", "", "Cherry", "fresh"},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Fatalf("expected %v, got %v", expected, rows)
+	}
+	if formula, _ := result.GetCellFormula("Prices", "C2"); formula != "B2*2" {
+		t.Fatalf("expected the formula to be kept, got %q", formula)
+	}
+	if style, _ := result.GetCellStyle("Prices", "B2"); style != highlight {
+		t.Fatalf("expected the price style to be kept, got %d", style)
+	}
+
+	read, err := ReadExcel[price](bytes.NewReader(upserted.Bytes()), WithSheet("Prices"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 3 || read[0].Name != data[0].Name || read[0].Notes == nil || *read[0].Notes != "seasonal" || read[2].Notes == nil || *read[2].Notes != fresh {
+		t.Fatalf("unexpected prices %+v", read)
+	}
+
+	type unkeyed struct {
+		Name string
+	}
+	if err := UpsertExcel(file, []unkeyed{{Name: "x"}}, WithSheet("Prices")); err == nil {
+		t.Fatal("expected a type without a primary column to be rejected")
+	}
+}